@@ -0,0 +1,138 @@
+package kms
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time for a Manager's timers, see SetClock()
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+
+	// NewTimer creates a new Timer that will send the current time on it's channel after at least
+	// duration d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event timer created by a Clock, see time.Timer
+type Timer interface {
+	// C returns the channel on which the time is delivered.
+	C() <-chan time.Time
+
+	// Stop prevents the Timer from firing, returning false if the timer has already expired
+	// or been stopped.
+	Stop() bool
+
+	// Reset changes the timer to expire after duration d, returning true if the timer had
+	// been active.
+	Reset(d time.Duration) bool
+}
+
+// RealClock returns the Clock backed by the time package.
+func RealClock() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// WithClock sets the Clock used by the Manager's timers, see SetClock()
+func WithClock(c Clock) Option {
+	return func(m *Manager) {
+		m.SetClock(c)
+	}
+}
+
+// SetClock sets the Clock used by all of the Manager's timers; the ListenTimeout wait duration,
+// lame-duck period, escalation steps, hook and reload timeouts, diagnostics budget and the stuck
+// operation watchdog. Useful for simulating shutdown timing in tests, see the kmstest package.
+//
+// Default: RealClock()
+func (m *Manager) SetClock(c Clock) {
+	m.clock.Store(clockValue{c})
+}
+
+// Clock returns the Clock used by the Manager's timers.
+func (m *Manager) Clock() Clock {
+	return m.clock.Load().(clockValue).Clock
+}
+
+// withDeadline acts as context.WithDeadline using the Manager's Clock.
+func (m *Manager) withDeadline(parent context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+
+	clock := m.Clock()
+
+	if _, ok := clock.(realClock); ok {
+		return context.WithDeadline(parent, deadline)
+	}
+
+	ctx, cancel := context.WithCancelCause(parent)
+	dc := &deadlineContext{Context: ctx, deadline: deadline}
+
+	t := clock.NewTimer(deadline.Sub(clock.Now()))
+
+	go func() {
+		select {
+		case <-t.C():
+			cancel(context.DeadlineExceeded)
+		case <-ctx.Done():
+			t.Stop()
+		}
+	}()
+
+	return dc, func() { cancel(context.Canceled) }
+}
+
+// deadlineContext is the context returned by withDeadline for Clocks other than the real clock.
+type deadlineContext struct {
+	context.Context
+	deadline time.Time
+}
+
+func (c *deadlineContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *deadlineContext) Err() error {
+
+	if err := c.Context.Err(); err != nil && context.Cause(c.Context) == context.DeadlineExceeded {
+		return context.DeadlineExceeded
+	}
+
+	return c.Context.Err()
+}
+
+// clockValue gives the atomic.Value a consistent type whichever Clock implementation is stored
+type clockValue struct {
+	Clock
+}
+
+// SetClock sets the Clock used by the package's timers, see Manager.SetClock()
+//
+// Default: RealClock()
+func SetClock(c Clock) {
+	defaultManager.SetClock(c)
+}
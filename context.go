@@ -0,0 +1,141 @@
+package kms
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// shutdownContext is a context.Context whose deadline can be set after creation;
+// the deadline of a Managers contexts is only known once a shutdown begins.
+type shutdownContext struct {
+	context.Context
+	cancel   context.CancelCauseFunc
+	deadline atomic.Value // time.Time
+}
+
+var _ context.Context = new(shutdownContext)
+
+func newShutdownContext() *shutdownContext {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &shutdownContext{
+		Context: ctx,
+		cancel:  cancel,
+	}
+}
+
+// Deadline returns the shutdown deadline once it is known, otherwise the parent's.
+func (c *shutdownContext) Deadline() (deadline time.Time, ok bool) {
+	if deadline, ok = c.deadline.Load().(time.Time); ok {
+		return
+	}
+	return c.Context.Deadline()
+}
+
+func (c *shutdownContext) setDeadline(deadline time.Time) {
+	c.deadline.Store(deadline)
+}
+
+// Context returns a context.Context which is cancelled once a shutdown is initiated.
+//
+// When using ListenTimeout the context carries the deadline by which the process
+// will be forcefully shut down once the shutdown has been initiated.
+func (m *Manager) Context() context.Context {
+	return m.ctx.Load().(*shutdownContext)
+}
+
+// NotifyContext returns a copy of the parent context which is cancelled, with the shutdown
+// reason as it's cause, once a shutdown is initiated or when the returned stop function is
+// called, whichever happens first.
+//
+// Unlike Context(), which belongs to a single lifecycle, the returned context follows the Manager
+// across Reset() so that long lived goroutines, eg. workers started before a Reset, are still
+// notified of the next shutdown. Once cancelled by a shutdown it carries the same deadline as
+// Context().
+//
+// stop releases the resources associated with the context and should be called as soon as
+// it's no longer needed.
+func (m *Manager) NotifyContext(parent context.Context) (ctx context.Context, stop context.CancelFunc) {
+
+	c, cancel := context.WithCancelCause(parent)
+	nc := &shutdownContext{Context: c, cancel: cancel}
+
+	notify, sctx, reset := m.lifecycle()
+
+	go func() {
+		for {
+			// a lifecycle whose shutdown was initiated before it was Reset still counts
+			select {
+			case <-notify:
+				notifyContext(nc, sctx)
+				return
+			default:
+			}
+
+			select {
+			case <-notify:
+				notifyContext(nc, sctx)
+				return
+			case <-reset:
+				notify, sctx, reset = m.lifecycle()
+			case <-c.Done():
+				return
+			}
+		}
+	}()
+
+	return nc, func() { cancel(nil) }
+}
+
+// lifecycle returns the current lifecycle's notify channel, context and the stop channel
+// closed when it's Reset.
+func (m *Manager) lifecycle() (notify chan struct{}, ctx *shutdownContext, reset chan struct{}) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.notify.Load().(chan struct{}), m.ctx.Load().(*shutdownContext), m.stop.Load().(chan struct{})
+}
+
+// notifyContext cancels the context returned by NotifyContext once the lifecycle's shutdown
+// has been initiated.
+func notifyContext(nc, sctx *shutdownContext) {
+
+	// the lifecycle's context is cancelled, with the reason, just after notify is closed.
+	<-sctx.Done()
+
+	if deadline, ok := sctx.Deadline(); ok {
+		nc.setDeadline(deadline)
+	}
+
+	nc.cancel(context.Cause(sctx))
+}
+
+// HardStopContext returns a context.Context which is cancelled just before the process
+// is forcefully terminated, or once the shutdown has completed.
+//
+// useful for operations that should be allowed to continue during the graceful
+// shutdown, but must unwind before the process exits.
+func (m *Manager) HardStopContext() context.Context {
+	return m.hardCtx.Load().(*shutdownContext)
+}
+
+// Context returns a context.Context which is cancelled once a shutdown is initiated.
+//
+// When using ListenTimeout the context carries the deadline by which the process
+// will be forcefully shut down once the shutdown has been initiated.
+func Context() context.Context {
+	return defaultManager.Context()
+}
+
+// NotifyContext returns a copy of the parent context which is cancelled once a shutdown is
+// initiated, following the package across Reset(), see Manager.NotifyContext()
+func NotifyContext(parent context.Context) (ctx context.Context, stop context.CancelFunc) {
+	return defaultManager.NotifyContext(parent)
+}
+
+// HardStopContext returns a context.Context which is cancelled just before the process
+// is forcefully terminated, or once the shutdown has completed.
+func HardStopContext() context.Context {
+	return defaultManager.HardStopContext()
+}
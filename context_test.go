@@ -0,0 +1,132 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestContext(t *testing.T) {
+
+	sig := make(chan os.Signal, 1)
+	m := New(WithSignalFn(chanSignalFn(sig)))
+
+	ctx := m.Context()
+
+	if _, ok := ctx.Deadline(); ok {
+		t.Errorf("Expected no deadline before shutdown")
+	}
+
+	m.Wait()
+	m.ListenTimeout(false, time.Minute)
+
+	sig <- syscall.SIGTERM
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected context to be cancelled on shutdown")
+	}
+
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Errorf("Expected '%v' Got '%v'", context.Canceled, ctx.Err())
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatalf("Expected deadline once shutdown initiated")
+	}
+
+	if until := time.Until(deadline); until <= 0 || until > time.Minute {
+		t.Errorf("Expected deadline within timeout Got '%s'", until)
+	}
+
+	hard := m.HardStopContext()
+
+	select {
+	case <-hard.Done():
+		t.Fatalf("Expected hard stop context to remain active while draining")
+	default:
+	}
+
+	m.Done()
+
+	select {
+	case <-hard.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected hard stop context to be cancelled on completion")
+	}
+}
+
+func TestHardStopContextBeforeExit(t *testing.T) {
+
+	sig := make(chan os.Signal, 1)
+	exited := make(chan bool, 1)
+
+	var m *Manager
+
+	m = New(
+		WithSignalFn(chanSignalFn(sig)),
+		WithExitFunc(func(code int) {
+			exited <- m.HardStopContext().Err() != nil
+		}),
+	)
+
+	m.Wait()
+	m.ListenTimeout(false, time.Millisecond*10)
+
+	sig <- syscall.SIGTERM
+
+	select {
+	case cancelled := <-exited:
+		if !cancelled {
+			t.Errorf("Expected hard stop context cancelled before exit")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected timeout exit")
+	}
+}
+
+func TestNotifyContext(t *testing.T) {
+
+	m := New(WithSignalFn(chanSignalFn(make(chan os.Signal))))
+
+	ctx, stop := m.NotifyContext(context.Background())
+	defer stop()
+
+	// the context follows the Manager into the next lifecycle
+	if err := m.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	errReason := errors.New("reason")
+
+	m.ListenTimeout(false, time.Minute)
+	m.Shutdown(errReason)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected context to be cancelled on shutdown after a reset")
+	}
+
+	if cause := context.Cause(ctx); cause != errReason {
+		t.Errorf("Expected '%v' Got '%v'", errReason, cause)
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		t.Errorf("Expected deadline once shutdown initiated")
+	}
+
+	<-m.ShutdownComplete()
+
+	ctx, stop = m.NotifyContext(context.Background())
+	stop()
+
+	if !errors.Is(ctx.Err(), context.Canceled) || context.Cause(ctx) != context.Canceled {
+		t.Errorf("Expected '%v' Got '%v'", context.Canceled, context.Cause(ctx))
+	}
+}
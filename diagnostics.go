@@ -0,0 +1,130 @@
+package kms
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"time"
+)
+
+// diagnosticsBudget is the maximum amount of time writing diagnostics may delay the forced exit.
+const diagnosticsBudget = time.Second * 2
+
+// diagnostics is the configured destination of the diagnostics written when a shutdown times out
+type diagnostics struct {
+	w    io.Writer
+	path string
+}
+
+// WithDiagnostics sets the writer diagnostics are written to, see SetDiagnostics()
+func WithDiagnostics(w io.Writer) Option {
+	return func(m *Manager) {
+		m.SetDiagnostics(w)
+	}
+}
+
+// SetDiagnostics sets the writer that diagnostics are written to when the ListenTimeout wait
+// duration expires, just before the forced exit. The diagnostics include the outstanding
+// operations, basic runtime stats and all goroutine stacks; writing them is limited to a small
+// fixed budget so as not to delay the exit indefinitely. Passing nil disables diagnostics.
+//
+// Default: disabled
+func (m *Manager) SetDiagnostics(w io.Writer) {
+	m.diagnostics.Store(diagnostics{w: w})
+}
+
+// SetDiagnosticsFile acts identically to SetDiagnostics, except that the diagnostics are written
+// to the file at path, which is created or truncated at the time the diagnostics are written.
+func (m *Manager) SetDiagnosticsFile(path string) {
+	m.diagnostics.Store(diagnostics{path: path})
+}
+
+// writeDiagnostics writes the configured diagnostics, blocking no longer than diagnosticsBudget.
+func (m *Manager) writeDiagnostics(reason error) {
+
+	d := m.diagnostics.Load().(diagnostics)
+	if d.w == nil && d.path == "" {
+		return
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		w := d.w
+
+		if d.path != "" {
+
+			f, err := os.Create(d.path)
+			if err != nil {
+				m.Logger().Error("writing diagnostics", "path", d.path, "error", err)
+				return
+			}
+			defer f.Close()
+
+			w = f
+		}
+
+		if err := m.WriteDiagnostics(w, reason); err != nil {
+			m.Logger().Error("writing diagnostics", "error", err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-m.Clock().After(diagnosticsBudget):
+		m.Logger().Error("writing diagnostics exceeded budget", "budget", diagnosticsBudget)
+	}
+}
+
+// WriteDiagnostics writes the outstanding operations, basic runtime stats and all goroutine stacks
+// to w; the same diagnostics that are written when a shutdown times out.
+func (m *Manager) WriteDiagnostics(w io.Writer, reason error) error {
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	ops := m.InFlight()
+
+	fmt.Fprintf(w, "kms diagnostics %s\n", m.Clock().Now().Format(time.RFC3339Nano))
+
+	if reason != nil {
+		fmt.Fprintf(w, "shutdown reason: %s\n", reason)
+	}
+
+	fmt.Fprintf(w, "\n--- runtime ---\n")
+	fmt.Fprintf(w, "pid: %d\n", os.Getpid())
+	fmt.Fprintf(w, "go version: %s\n", runtime.Version())
+	fmt.Fprintf(w, "gomaxprocs: %d\n", runtime.GOMAXPROCS(0))
+	fmt.Fprintf(w, "goroutines: %d\n", runtime.NumGoroutine())
+	fmt.Fprintf(w, "heap alloc: %d bytes\n", ms.HeapAlloc)
+	fmt.Fprintf(w, "sys: %d bytes\n", ms.Sys)
+	fmt.Fprintf(w, "gc cycles: %d\n", ms.NumGC)
+
+	fmt.Fprintf(w, "\n--- %d operation(s) in-flight ---\n", len(ops))
+
+	for _, op := range ops {
+		fmt.Fprintf(w, "%s\n%s\n", op, op.Stack)
+	}
+
+	fmt.Fprintf(w, "\n--- goroutines ---\n")
+
+	return pprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// SetDiagnostics sets the writer that diagnostics are written to when the ListenTimeout wait
+// duration expires, just before the forced exit. Passing nil disables diagnostics.
+//
+// Default: disabled
+func SetDiagnostics(w io.Writer) {
+	defaultManager.SetDiagnostics(w)
+}
+
+// SetDiagnosticsFile acts identically to SetDiagnostics, except that the diagnostics are written
+// to the file at path, which is created or truncated at the time the diagnostics are written.
+func SetDiagnosticsFile(path string) {
+	defaultManager.SetDiagnosticsFile(path)
+}
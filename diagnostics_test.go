@@ -0,0 +1,47 @@
+package kms
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDiagnosticsOnTimeout(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "diagnostics.txt")
+	sig := make(chan os.Signal, 1)
+	exited := make(chan int, 1)
+
+	m := New(
+		WithSignalFn(chanSignalFn(sig)),
+		WithExitFunc(func(code int) { exited <- code }),
+	)
+
+	m.SetDiagnosticsFile(path)
+	m.Track("stuck.job", "id", "42")
+	m.ListenTimeout(false, time.Millisecond*10)
+
+	sig <- syscall.SIGTERM
+
+	select {
+	case <-exited:
+	case <-time.After(diagnosticsBudget + time.Second):
+		t.Fatalf("Expected timeout exit")
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := string(b)
+
+	for _, s := range []string{"shutdown reason: kms: received signal terminated", "goroutines:", "1 operation(s) in-flight", "stuck.job id=42", "TestDiagnosticsOnTimeout", "--- goroutines ---", "goroutine "} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Expected diagnostics to contain '%s'", s)
+		}
+	}
+}
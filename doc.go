@@ -2,6 +2,7 @@
 Package kms (Killing Me Softly) is a library that aids in graceful shutdown of a process/application.
 
 Example:
+
 	package main
 
 	import (
@@ -23,5 +24,16 @@ Example:
 
 		kmshttp.ListenAndServe(":3007", nil)
 	}
+
+# Managers
+
+The package level functions operate on a default Manager, see kms.Default(). When
+multiple independent shutdown domains are needed within the same process, each can
+be created using kms.New() and handed to kmsnet and kmshttp using the WithManager variants.
+
+	m := kms.New(kms.WithSignalFn(mySignalFn))
+	m.ListenTimeout(false, time.Minute*3)
+
+	kmshttp.ListenAndServeWithManager(m, ":3008", nil)
 */
 package kms
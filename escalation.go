@@ -0,0 +1,233 @@
+package kms
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Step is a step of the escalation ladder taken once a shutdown overruns it's ListenTimeout
+// wait duration, or is hurried along by repeated signals, see SetEscalation()
+type Step uint8
+
+// Escalation steps, in the order they are taken
+const (
+	// StepDrain is the soft drain, in-flight operations are waited on.
+	StepDrain Step = iota
+
+	// StepHardStop cancels the HardStopContext().
+	StepHardStop
+
+	// StepForceClose runs the force close hooks eg. kmsnet closing all active connections,
+	// see OnForceClose().
+	StepForceClose
+
+	// StepLastGasp runs the last gasp hooks, see OnLastGasp(), after which the process exits.
+	StepLastGasp
+)
+
+// String returns the name of the Step
+func (s Step) String() string {
+	switch s {
+	case StepDrain:
+		return "Drain"
+	case StepHardStop:
+		return "HardStop"
+	case StepForceClose:
+		return "ForceClose"
+	case StepLastGasp:
+		return "LastGasp"
+	default:
+		return fmt.Sprintf("Step(%d)", uint8(s))
+	}
+}
+
+// Escalation configures the escalation ladder.
+//
+// When the ListenTimeout wait duration expires, T1, the HardStopContext() is cancelled; connections
+// are force closed ForceCloseAfter later, T2, and LastGaspAfter after that, T3, the last gasp hooks
+// are run before the process exits.
+//
+// When AllowSignalHardShutdown is enabled each additional signal skips the remaining wait and
+// takes the next step immediately, starting with StepHardStop.
+type Escalation struct {
+	// ForceCloseAfter is how long after the hard stop context is cancelled that connections are
+	// force closed.
+	ForceCloseAfter time.Duration
+
+	// LastGaspAfter is how long after connections are force closed that the last gasp hooks are run.
+	LastGaspAfter time.Duration
+
+	// LastGaspTimeout is the maximum amount of time the last gasp hooks are allowed to run
+	// before the process exits.
+	LastGaspTimeout time.Duration
+}
+
+// DefaultEscalation returns the default Escalation; each step is taken immediately after the previous
+// with the last gasp hooks given up to 5 seconds.
+func DefaultEscalation() Escalation {
+	return Escalation{
+		LastGaspTimeout: time.Second * 5,
+	}
+}
+
+// WithEscalation sets the escalation ladder, see SetEscalation()
+func WithEscalation(e Escalation) Option {
+	return func(m *Manager) {
+		m.SetEscalation(e)
+	}
+}
+
+// SetEscalation sets the escalation ladder taken once a shutdown overruns it's ListenTimeout
+// wait duration, or another shutdown signal is received, see Escalation.
+//
+// Default: DefaultEscalation()
+func (m *Manager) SetEscalation(e Escalation) {
+	m.escalation.Store(e)
+}
+
+// OnForceClose registers a hook run at StepForceClose of the escalation ladder; it should
+// forcefully close resources still holding up the shutdown eg. client connections.
+//
+// Hooks are run concurrently and are not waited on. The returned func unregisters the hook
+// eg. once the resources it closes have been released.
+func (m *Manager) OnForceClose(name string, fn HookFunc, opts ...HookOption) (unregister func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.addHook(&m.forceClosers, newHook(name, fn, opts))
+}
+
+// OnLastGasp registers a hook run at StepLastGasp of the escalation ladder, just before the
+// process exits eg. flushing logs or metrics.
+//
+// Hooks are run concurrently and given at most Escalation.LastGaspTimeout to complete.
+func (m *Manager) OnLastGasp(name string, fn HookFunc, opts ...HookOption) {
+	m.mu.Lock()
+	m.lastGasps = append(m.lastGasps, newHook(name, fn, opts))
+	m.mu.Unlock()
+}
+
+// escalate climbs the escalation ladder from StepHardStop to exiting the process, each signal
+// received skips the remaining wait of the current step. It returns without exiting if the
+// shutdown completes in the meantime.
+func (m *Manager) escalate(outcome Outcome, reason error, signals <-chan os.Signal, done <-chan struct{}) {
+
+	esc := m.escalation.Load().(Escalation)
+	ctx := context.WithValue(context.Background(), reasonKey{}, reason)
+
+	m.emit(Event{Type: EventHardStop, Step: StepHardStop, InFlight: m.inFlightCount()})
+	m.hardCtx.Load().(*shutdownContext).cancel(nil)
+
+	if !m.escalationWait(esc.ForceCloseAfter, &signals, done) {
+		return
+	}
+
+	m.mu.Lock()
+	closers := append([]hook(nil), m.forceClosers...)
+	m.mu.Unlock()
+
+	m.emit(Event{Type: EventForceClose, Step: StepForceClose, InFlight: m.inFlightCount()})
+	go m.runHooks(ctx, closers, time.Time{}, m.escalationHookFailed(StepForceClose))
+
+	if !m.escalationWait(esc.LastGaspAfter, &signals, done) {
+		return
+	}
+
+	m.mu.Lock()
+	gasps := append([]hook(nil), m.lastGasps...)
+	m.mu.Unlock()
+
+	m.emit(Event{Type: EventLastGasp, Step: StepLastGasp, InFlight: m.inFlightCount()})
+
+	var deadline time.Time
+
+	if esc.LastGaspTimeout > 0 {
+		deadline = m.Clock().Now().Add(esc.LastGaspTimeout)
+	}
+
+	gasped := make(chan struct{})
+
+	go func() {
+		m.runHooks(ctx, gasps, deadline, m.escalationHookFailed(StepLastGasp))
+		close(gasped)
+	}()
+
+	// a signal while the last gasp hooks are running exits immediately
+	for waiting := true; waiting; {
+		select {
+		case <-gasped:
+			waiting = false
+		case sig, ok := <-signals:
+			if !ok {
+				signals = nil
+				continue
+			}
+			m.emit(Event{Type: EventHardShutdownRequested, Signal: sig})
+			waiting = false
+		}
+	}
+
+	m.forceExit(outcome, reason)
+}
+
+// escalationWait waits for d before the next step is taken, returning false if the shutdown
+// completes in the meantime; a signal ends the wait early.
+func (m *Manager) escalationWait(d time.Duration, signals *<-chan os.Signal, done <-chan struct{}) bool {
+
+	select {
+	case <-done:
+		return false
+	default:
+	}
+
+	if d <= 0 {
+		return true
+	}
+
+	t := m.Clock().NewTimer(d)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C():
+			return true
+		case sig, ok := <-*signals:
+			// a closed signal channel is not a signal
+			if !ok {
+				*signals = nil
+				continue
+			}
+			m.emit(Event{Type: EventHardShutdownRequested, Signal: sig})
+			return true
+		case <-done:
+			return false
+		}
+	}
+}
+
+func (m *Manager) escalationHookFailed(step Step) func(hook, error) {
+	return func(h hook, err error) {
+		m.Logger().Error("escalation hook failed", "step", step.String(), "hook", h.name, "error", err)
+	}
+}
+
+// SetEscalation sets the escalation ladder taken once a shutdown overruns it's ListenTimeout
+// wait duration, or another shutdown signal is received, see Escalation.
+//
+// Default: DefaultEscalation()
+func SetEscalation(e Escalation) {
+	defaultManager.SetEscalation(e)
+}
+
+// OnForceClose registers a hook run at StepForceClose of the escalation ladder; it should
+// forcefully close resources still holding up the shutdown eg. client connections.
+func OnForceClose(name string, fn HookFunc, opts ...HookOption) (unregister func()) {
+	return defaultManager.OnForceClose(name, fn, opts...)
+}
+
+// OnLastGasp registers a hook run at StepLastGasp of the escalation ladder, just before the
+// process exits eg. flushing logs or metrics.
+func OnLastGasp(name string, fn HookFunc, opts ...HookOption) {
+	defaultManager.OnLastGasp(name, fn, opts...)
+}
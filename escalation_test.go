@@ -0,0 +1,134 @@
+package kms
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestEscalation(t *testing.T) {
+
+	exited := make(chan int, 1)
+
+	m := New(
+		WithSignalFn(chanSignalFn(make(chan os.Signal))),
+		WithExitFunc(func(code int) { exited <- code }),
+		WithEscalation(Escalation{
+			ForceCloseAfter: time.Millisecond * 50,
+			LastGaspAfter:   time.Millisecond * 50,
+			LastGaspTimeout: time.Second,
+		}),
+	)
+
+	steps := make(chan Step, 3)
+
+	m.OnForceClose("conns", func(ctx context.Context) error {
+		if m.HardStopContext().Err() == nil {
+			t.Errorf("Expected hard stop context to be cancelled before force close")
+		}
+		steps <- StepForceClose
+		return nil
+	})
+
+	unregister := m.OnForceClose("released", func(ctx context.Context) error {
+		t.Errorf("Expected unregistered force close hook not to run")
+		return nil
+	})
+	unregister()
+
+	m.OnLastGasp("flush", func(ctx context.Context) error {
+		steps <- StepLastGasp
+		return nil
+	})
+
+	sub := m.Subscribe()
+	defer sub.Close()
+
+	m.Wait()
+	m.ListenTimeout(false, time.Millisecond*50)
+	m.Shutdown(nil)
+
+	select {
+	case code := <-exited:
+		if code != 1 {
+			t.Errorf("Expected '%d' Got '%d'", 1, code)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatalf("Expected exit once the ladder completes")
+	}
+
+	if s := <-steps; s != StepForceClose {
+		t.Errorf("Expected '%s' Got '%s'", StepForceClose, s)
+	}
+
+	if s := <-steps; s != StepLastGasp {
+		t.Errorf("Expected '%s' Got '%s'", StepLastGasp, s)
+	}
+
+	var got []EventType
+
+	for len(sub.Events()) > 0 {
+		if e := <-sub.Events(); e.Type >= EventTimeout && e.Type != EventShutdownComplete {
+			got = append(got, e.Type)
+		}
+	}
+
+	expected := []EventType{EventTimeout, EventHardStop, EventForceClose, EventLastGasp, EventExiting}
+
+	if len(got) != len(expected) {
+		t.Fatalf("Expected '%v' Got '%v'", expected, got)
+	}
+
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected '%s' Got '%s'", expected[i], got[i])
+		}
+	}
+}
+
+func TestEscalationSignalAdvances(t *testing.T) {
+
+	sig := make(chan os.Signal, 1)
+	exited := make(chan int, 1)
+
+	m := New(
+		WithSignalFn(chanSignalFn(sig)),
+		WithExitFunc(func(code int) { exited <- code }),
+		WithEscalation(Escalation{
+			ForceCloseAfter: time.Minute,
+			LastGaspAfter:   time.Minute,
+		}),
+	)
+
+	m.Wait()
+	m.Listen(false)
+
+	sig <- syscall.SIGINT
+	<-m.ShutdownInitiated()
+
+	// second signal cancels the hard stop context, rather than exiting
+	sig <- syscall.SIGINT
+
+	select {
+	case <-m.HardStopContext().Done():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected hard stop context to be cancelled")
+	}
+
+	select {
+	case <-exited:
+		t.Fatalf("Expected exit to wait for the remaining steps")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	sig <- syscall.SIGINT
+	sig <- syscall.SIGINT
+
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected exit after the last step")
+	}
+}
@@ -0,0 +1,279 @@
+package kms
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventBuffer is the number of events buffered for each Subscription, once full further
+// events are dropped for that Subscription, see Subscription.Dropped()
+const EventBuffer = 64
+
+// EventType identifies a lifecycle transition, see Subscribe()
+type EventType uint8
+
+// Lifecycle events
+const (
+	// EventSignalReceived is emitted when a shutdown signal is received, Signal is set.
+	EventSignalReceived EventType = iota
+
+	// EventShutdownInitiated is emitted once a shutdown has been initiated, Reason is set.
+	EventShutdownInitiated
+
+	// EventLameDuckStarted is emitted when the lame-duck period starts, Duration is the period.
+	EventLameDuckStarted
+
+	// EventDrainStarted is emitted when in-flight operations start draining, Duration is the
+	// ListenTimeout wait duration, zero when waiting indefinitely.
+	EventDrainStarted
+
+	// EventHardShutdownRequested is emitted when a signal requests the process exits immediately,
+	// Signal is set.
+	EventHardShutdownRequested
+
+	// EventTimeout is emitted when the ListenTimeout wait duration expires, Duration is the timeout.
+	EventTimeout
+
+	// EventShutdownComplete is emitted once the shutdown has completed, Duration is how long the
+	// shutdown took.
+	EventShutdownComplete
+
+	// EventExiting is emitted just before the exit function is called, Outcome and Code are set.
+	EventExiting
+
+	// EventReloadRequested is emitted when a reload is requested by a signal, Reason is set.
+	EventReloadRequested
+
+	// EventReloadStarted is emitted when the reload handlers start running.
+	EventReloadStarted
+
+	// EventReloadComplete is emitted once all reload handlers have run, Duration is how long they
+	// took and Err the joined handler errors, if any.
+	EventReloadComplete
+
+	// EventOperationStuck is emitted when an in-flight operation is older than the configured age,
+	// Operation is set, see SetStuckOperationAge().
+	EventOperationStuck
+
+	// EventOperationLeaked is emitted when an Operation is garbage collected before Done was called,
+	// Operation is set, see SetLeakDetection().
+	EventOperationLeaked
+
+	// EventHardStop is emitted when the escalation ladder cancels the HardStopContext(), Step
+	// is StepHardStop, see SetEscalation().
+	EventHardStop
+
+	// EventForceClose is emitted when the escalation ladder runs the force close hooks, Step
+	// is StepForceClose.
+	EventForceClose
+
+	// EventLastGasp is emitted when the escalation ladder runs the last gasp hooks, Step
+	// is StepLastGasp.
+	EventLastGasp
+)
+
+// String returns the name of the EventType
+func (t EventType) String() string {
+	switch t {
+	case EventSignalReceived:
+		return "SignalReceived"
+	case EventShutdownInitiated:
+		return "ShutdownInitiated"
+	case EventLameDuckStarted:
+		return "LameDuckStarted"
+	case EventDrainStarted:
+		return "DrainStarted"
+	case EventHardShutdownRequested:
+		return "HardShutdownRequested"
+	case EventTimeout:
+		return "Timeout"
+	case EventShutdownComplete:
+		return "ShutdownComplete"
+	case EventExiting:
+		return "Exiting"
+	case EventReloadRequested:
+		return "ReloadRequested"
+	case EventReloadStarted:
+		return "ReloadStarted"
+	case EventReloadComplete:
+		return "ReloadComplete"
+	case EventOperationStuck:
+		return "OperationStuck"
+	case EventOperationLeaked:
+		return "OperationLeaked"
+	case EventHardStop:
+		return "HardStop"
+	case EventForceClose:
+		return "ForceClose"
+	case EventLastGasp:
+		return "LastGasp"
+	default:
+		return fmt.Sprintf("EventType(%d)", uint8(t))
+	}
+}
+
+// Event describes a lifecycle transition of a Manager, which fields are set depends on
+// the EventType.
+type Event struct {
+	Type     EventType
+	Time     time.Time
+	Signal   os.Signal
+	Reason   error
+	Err      error
+	Outcome  Outcome
+	Code     int
+	Step     Step
+	InFlight int
+	Duration time.Duration
+
+	// Operation describes the operation of EventOperationStuck and EventOperationLeaked events.
+	Operation OperationInfo
+}
+
+// Subscription is a stream of lifecycle events, see Subscribe()
+type Subscription struct {
+	m       *Manager
+	c       chan Event
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// Events returns the channel events are delivered on, it is closed by Close()
+func (s *Subscription) Events() <-chan Event {
+	return s.c
+}
+
+// Dropped returns the number of events dropped because the subscriber was not keeping up.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes and closes the events channel.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.m.subsMu.Lock()
+		delete(s.m.subs, s)
+		close(s.c)
+		s.m.subsMu.Unlock()
+	})
+}
+
+// Subscribe returns a Subscription receiving the Managers lifecycle events.
+//
+// Delivery never blocks the Manager, each Subscription buffers up to EventBuffer events after
+// which further events are dropped and counted, see Subscription.Dropped()
+func (m *Manager) Subscribe() *Subscription {
+
+	s := &Subscription{
+		m: m,
+		c: make(chan Event, EventBuffer),
+	}
+
+	m.subsMu.Lock()
+
+	if m.subs == nil {
+		m.subs = make(map[*Subscription]struct{})
+	}
+
+	m.subs[s] = struct{}{}
+	m.subsMu.Unlock()
+
+	return s
+}
+
+// emit logs the event and delivers it to all subscribers.
+func (m *Manager) emit(e Event) {
+
+	if e.Time.IsZero() {
+		e.Time = m.Clock().Now()
+	}
+
+	m.logEvent(e)
+
+	m.subsMu.RLock()
+	defer m.subsMu.RUnlock()
+
+	for s := range m.subs {
+		select {
+		case s.c <- e:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+func (m *Manager) logEvent(e Event) {
+
+	log := m.Logger()
+
+	switch e.Type {
+	case EventSignalReceived:
+		log.Info("signal received", "signal", signalName(e.Signal))
+
+	case EventShutdownInitiated:
+		log.Info("shutdown initiated", "reason", e.Reason.Error())
+
+	case EventLameDuckStarted:
+		log.Info("lame duck started", "duration", e.Duration)
+
+	case EventDrainStarted:
+		if e.Duration > 0 {
+			log.Info("drain started", "in_flight", e.InFlight, "timeout", e.Duration)
+		} else {
+			log.Info("drain started", "in_flight", e.InFlight)
+		}
+
+	case EventHardShutdownRequested:
+		log.Warn("hard shutdown requested", "signal", signalName(e.Signal))
+
+	case EventTimeout:
+		log.Error("shutdown timed out", "timeout", e.Duration, "in_flight", e.InFlight)
+
+	case EventShutdownComplete:
+		log.Info("shutdown complete", "duration", e.Duration)
+
+	case EventExiting:
+		log.Warn("exiting", "outcome", e.Outcome.String(), "code", e.Code)
+
+	case EventReloadRequested:
+		log.Info("reload requested", "reason", e.Reason.Error())
+
+	case EventReloadStarted:
+		log.Info("reload started")
+
+	case EventReloadComplete:
+		if e.Err != nil {
+			log.Error("reload failed", "duration", e.Duration, "error", e.Err)
+		} else {
+			log.Info("reload complete", "duration", e.Duration)
+		}
+
+	case EventOperationStuck:
+		op := e.Operation
+		log.Warn("operation stuck", "operation", op.Name, "labels", op.Labels, "running_for", e.Time.Sub(op.Started), "stack", op.Stack)
+
+	case EventOperationLeaked:
+		op := e.Operation
+		log.Error("operation leaked, garbage collected before Done", "operation", op.Name, "labels", op.Labels, "running_for", e.Time.Sub(op.Started), "stack", op.Stack)
+
+	case EventHardStop:
+		log.Warn("hard stop context cancelled", "step", e.Step.String(), "in_flight", e.InFlight)
+
+	case EventForceClose:
+		log.Warn("force closing connections", "step", e.Step.String(), "in_flight", e.InFlight)
+
+	case EventLastGasp:
+		log.Warn("running last gasp hooks", "step", e.Step.String(), "in_flight", e.InFlight)
+	}
+}
+
+// Subscribe returns a Subscription receiving the package's lifecycle events.
+//
+// Delivery never blocks, each Subscription buffers up to EventBuffer events after which further
+// events are dropped and counted, see Subscription.Dropped()
+func Subscribe() *Subscription {
+	return defaultManager.Subscribe()
+}
@@ -0,0 +1,83 @@
+package kms
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+
+	sig := make(chan os.Signal, 1)
+	m := New(
+		WithSignalFn(chanSignalFn(sig)),
+		WithLameDuck(time.Millisecond*10),
+	)
+
+	sub := m.Subscribe()
+	defer sub.Close()
+
+	m.ListenTimeout(false, time.Minute)
+	sig <- syscall.SIGTERM
+	<-m.ShutdownComplete()
+
+	expected := []EventType{
+		EventSignalReceived,
+		EventShutdownInitiated,
+		EventLameDuckStarted,
+		EventDrainStarted,
+		EventShutdownComplete,
+	}
+
+	for _, typ := range expected {
+
+		select {
+		case e := <-sub.Events():
+			if e.Type != typ || e.Time.IsZero() {
+				t.Fatalf("Expected '%s' Got '%s'", typ, e.Type)
+			}
+
+			if typ == EventSignalReceived && e.Signal != syscall.SIGTERM {
+				t.Errorf("Expected '%v' Got '%v'", syscall.SIGTERM, e.Signal)
+			}
+
+			if typ == EventDrainStarted && e.Duration != time.Minute {
+				t.Errorf("Expected '%s' Got '%s'", time.Minute, e.Duration)
+			}
+
+		case <-time.After(time.Second):
+			t.Fatalf("Expected '%s' event", typ)
+		}
+	}
+}
+
+func TestSubscribeDropped(t *testing.T) {
+
+	m := New(WithSignalFn(chanSignalFn(make(chan os.Signal))))
+
+	sub := m.Subscribe()
+
+	for i := 0; i < EventBuffer+3; i++ {
+		m.emit(Event{Type: EventReloadStarted})
+	}
+
+	if sub.Dropped() != 3 {
+		t.Errorf("Expected '%d' dropped Got '%d'", 3, sub.Dropped())
+	}
+
+	sub.Close()
+	sub.Close()
+
+	// closed subscriptions no longer receive events
+	m.emit(Event{Type: EventReloadStarted})
+
+	n := 0
+	for range sub.Events() {
+		n++
+	}
+
+	if n != EventBuffer {
+		t.Errorf("Expected '%d' buffered events Got '%d'", EventBuffer, n)
+	}
+}
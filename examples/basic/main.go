@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/go-playground/kms"
+	"github.com/go-playground/kms/kmscron"
 	"github.com/go-playground/kms/kmsnet/kmshttp"
 )
 
@@ -17,7 +19,7 @@ var (
 func main() {
 
 	go fakeWebsocketHandler()
-	go cronJob()
+	kmscron.New().Add("cron", kmscron.Every(time.Second), cronJob)
 
 	kms.Listen(false)
 
@@ -30,21 +32,18 @@ func main() {
 	fmt.Println("CRON completed gracefully? ", complete)
 }
 
-// crude CRON job examples
-func cronJob() {
+// a long running job that fires every second, skipping runs while the previous one is
+// still running; no new run starts once a shutdown is initiated.
+func cronJob(ctx context.Context) error {
 
-	// a long running job that fires every x minutes
-	for {
-		time.Sleep(time.Second * 1)
-		kms.Wait()
-		complete = false
+	complete = false
 
-		// long running DB calls
-		time.Sleep(time.Second * 10)
+	// long running DB calls
+	time.Sleep(time.Second * 10)
 
-		complete = true
-		kms.Done()
-	}
+	complete = true
+
+	return nil
 }
 
 // faking a single WebSocket, just to show how
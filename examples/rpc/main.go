@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 	"net/rpc"
-	"syscall"
 	"time"
 
 	"github.com/go-playground/kms"
@@ -84,5 +83,6 @@ func testAndKill() {
 
 	fmt.Printf("Server returned '%s'\n", out)
 
-	syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+	// no need to signal our own process, start the same graceful shutdown directly
+	kms.Shutdown(nil)
 }
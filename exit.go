@@ -0,0 +1,136 @@
+package kms
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Outcome describes how a shutdown ended
+type Outcome uint8
+
+// Shutdown outcomes
+const (
+	// OutcomeClean means all in-flight operations drained before the shutdown completed.
+	OutcomeClean Outcome = iota
+
+	// OutcomeTimeout means the ListenTimeout wait duration expired before draining completed.
+	OutcomeTimeout
+
+	// OutcomeForced means a second signal forced a hard shutdown, see AllowSignalHardShutdown().
+	OutcomeForced
+)
+
+// String returns the name of the Outcome
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeClean:
+		return "clean"
+	case OutcomeTimeout:
+		return "timeout"
+	case OutcomeForced:
+		return "forced"
+	default:
+		return "unknown"
+	}
+}
+
+// ExitPolicy maps the outcome of a shutdown to the process exit code so that orchestrators
+// can tell the outcomes apart.
+type ExitPolicy struct {
+
+	// Clean is the exit code used when draining completes.
+	Clean int
+
+	// Timeout is the exit code used when the ListenTimeout wait duration expires.
+	Timeout int
+
+	// Forced is the exit code used when a second signal forces a hard shutdown.
+	Forced int
+
+	// Error is the exit code used instead of Clean when the shutdown was initiated
+	// using Shutdown() with an error reason.
+	Error int
+
+	// SignalCodes uses the 128+signo convention, instead of Clean or Forced, when the
+	// shutdown was initiated or forced by a signal.
+	SignalCodes bool
+
+	// ExitOnComplete terminates the process with the policies exit code once draining
+	// completes when Listen or ListenTimeout is blocking.
+	ExitOnComplete bool
+}
+
+// DefaultExitPolicy returns the ExitPolicy used when none has been set.
+func DefaultExitPolicy() ExitPolicy {
+	return ExitPolicy{
+		Clean:   0,
+		Timeout: 1,
+		Forced:  1,
+		Error:   1,
+	}
+}
+
+// Code returns the exit code for the provided outcome and shutdown reason.
+func (p ExitPolicy) Code(outcome Outcome, reason error) int {
+
+	if outcome == OutcomeTimeout {
+		return p.Timeout
+	}
+
+	var se *SignalError
+
+	isSignal := errors.As(reason, &se)
+
+	if p.SignalCodes && isSignal {
+		if signo, ok := se.Signal.(syscall.Signal); ok {
+			return 128 + int(signo)
+		}
+	}
+
+	if outcome == OutcomeForced {
+		return p.Forced
+	}
+
+	if reason != nil && !isSignal && !errors.Is(reason, ErrShutdownRequested) {
+		return p.Error
+	}
+
+	return p.Clean
+}
+
+// WithExitPolicy sets the ExitPolicy used by the Manager, see SetExitPolicy()
+func WithExitPolicy(p ExitPolicy) Option {
+	return func(m *Manager) {
+		m.SetExitPolicy(p)
+	}
+}
+
+// SetExitPolicy sets the ExitPolicy used to determine the exit code of the process.
+//
+// Default: DefaultExitPolicy()
+func (m *Manager) SetExitPolicy(p ExitPolicy) {
+	m.exitPolicy.Store(p)
+}
+
+// ExitCode returns the exit code, according to the ExitPolicy, for the shutdown once
+// ShutdownComplete() has closed; useful when exiting the process yourself.
+//
+// eg. os.Exit(kms.ExitCode())
+func (m *Manager) ExitCode() int {
+	return m.exitPolicy.Load().(ExitPolicy).Code(OutcomeClean, m.ShutdownReason())
+}
+
+// SetExitPolicy sets the ExitPolicy used to determine the exit code of the process.
+//
+// Default: DefaultExitPolicy()
+func SetExitPolicy(p ExitPolicy) {
+	defaultManager.SetExitPolicy(p)
+}
+
+// ExitCode returns the exit code, according to the ExitPolicy, for the shutdown once
+// ShutdownComplete() has closed; useful when exiting the process yourself.
+//
+// eg. os.Exit(kms.ExitCode())
+func ExitCode() int {
+	return defaultManager.ExitCode()
+}
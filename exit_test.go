@@ -0,0 +1,96 @@
+package kms
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestExitPolicyCode(t *testing.T) {
+
+	p := DefaultExitPolicy()
+	p.Clean, p.Timeout, p.Forced, p.Error = 0, 2, 3, 4
+
+	sigterm := &SignalError{Signal: syscall.SIGTERM}
+
+	tests := []struct {
+		outcome  Outcome
+		reason   error
+		signals  bool
+		expected int
+	}{
+		{OutcomeClean, sigterm, false, 0},
+		{OutcomeClean, ErrShutdownRequested, false, 0},
+		{OutcomeClean, errors.New("fatal"), false, 4},
+		{OutcomeTimeout, sigterm, true, 2},
+		{OutcomeForced, sigterm, false, 3},
+		{OutcomeClean, sigterm, true, 128 + int(syscall.SIGTERM)},
+		{OutcomeForced, &SignalError{Signal: syscall.SIGINT}, true, 128 + int(syscall.SIGINT)},
+		{OutcomeClean, errors.New("fatal"), true, 4},
+	}
+
+	for i, tt := range tests {
+
+		p.SignalCodes = tt.signals
+
+		if code := p.Code(tt.outcome, tt.reason); code != tt.expected {
+			t.Errorf("Index: %d Expected '%d' Got '%d'", i, tt.expected, code)
+		}
+	}
+}
+
+func TestExitOnComplete(t *testing.T) {
+
+	exited := make(chan int, 1)
+
+	p := DefaultExitPolicy()
+	p.ExitOnComplete = true
+
+	m := New(
+		WithSignalFn(chanSignalFn(make(chan os.Signal))),
+		WithExitPolicy(p),
+		WithExitFunc(func(code int) { exited <- code }),
+	)
+
+	m.Shutdown(errors.New("fatal"))
+	m.Listen(true)
+
+	select {
+	case code := <-exited:
+		if code != p.Error {
+			t.Errorf("Expected '%d' Got '%d'", p.Error, code)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected exit on complete")
+	}
+}
+
+func TestExitTimeoutCode(t *testing.T) {
+
+	exited := make(chan int, 1)
+
+	p := DefaultExitPolicy()
+	p.Timeout = 124
+
+	sig := make(chan os.Signal, 1)
+	m := New(
+		WithSignalFn(chanSignalFn(sig)),
+		WithExitPolicy(p),
+		WithExitFunc(func(code int) { exited <- code }),
+	)
+
+	m.Wait()
+	m.ListenTimeout(false, time.Millisecond*10)
+	sig <- syscall.SIGTERM
+
+	select {
+	case code := <-exited:
+		if code != 124 {
+			t.Errorf("Expected '%d' Got '%d'", 124, code)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected timeout exit")
+	}
+}
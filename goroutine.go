@@ -0,0 +1,123 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// GoError is the error recorded when a goroutine started using Go returns an error or panics.
+type GoError struct {
+	Name string
+	Err  error
+}
+
+// Error returns the goroutine error's string representation
+func (e *GoError) Error() string {
+	return fmt.Sprintf("kms: goroutine %q: %s", e.Name, e.Err)
+}
+
+// Unwrap returns the underlying goroutine error
+func (e *GoError) Unwrap() error {
+	return e.Err
+}
+
+// WithShutdownOnError sets whether an error from a goroutine started using Go initiates
+// a graceful shutdown, see SetShutdownOnError()
+func WithShutdownOnError(enable bool) Option {
+	return func(m *Manager) {
+		m.SetShutdownOnError(enable)
+	}
+}
+
+// SetShutdownOnError sets whether an error, or panic, from a goroutine started using Go
+// initiates a graceful shutdown with the *GoError as the shutdown reason.
+//
+// Default: false
+func (m *Manager) SetShutdownOnError(enable bool) {
+	m.goShutdown.Store(enable)
+}
+
+// Go runs fn in a new goroutine tracked as the named operation, replacing the
+// Wait() + go func() { defer Done() }() pattern.
+//
+// fn is passed a NotifyContext(), which is cancelled once a shutdown is initiated, including one
+// after a Reset(); panics are recovered and returned errors collected, see Errors(). Returning the
+// context's error once it has been cancelled is not considered an error.
+func (m *Manager) Go(name string, fn func(ctx context.Context) error) {
+	m.spawn(m.track(name, nil, false), fn)
+}
+
+// spawn runs fn for the already tracked operation, Go and it's package level equivalent
+// track the operation themselves so that the recorded stack starts at their caller.
+func (m *Manager) spawn(op *Operation, fn func(ctx context.Context) error) {
+
+	name := op.Name()
+	ctx, stop := m.NotifyContext(context.Background())
+
+	go func() {
+		defer op.Done()
+		defer stop()
+
+		err := runGo(ctx, fn)
+
+		if err == nil || (ctx.Err() != nil && errors.Is(err, ctx.Err())) {
+			return
+		}
+
+		gerr := &GoError{Name: name, Err: err}
+
+		m.Logger().Error("goroutine failed", "name", name, "error", err)
+
+		m.mu.Lock()
+		m.goErrs = append(m.goErrs, gerr)
+		m.mu.Unlock()
+
+		if m.goShutdown.Load().(bool) {
+			m.Shutdown(gerr)
+		}
+	}()
+}
+
+func runGo(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return fn(ctx)
+}
+
+// Errors returns the errors, of type *GoError, collected from goroutines started using Go;
+// once ShutdownComplete() has closed all goroutines have returned.
+func (m *Manager) Errors() []error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]error(nil), m.goErrs...)
+}
+
+// SetShutdownOnError sets whether an error, or panic, from a goroutine started using Go
+// initiates a graceful shutdown with the *GoError as the shutdown reason.
+//
+// Default: false
+func SetShutdownOnError(enable bool) {
+	defaultManager.SetShutdownOnError(enable)
+}
+
+// Go runs fn in a new goroutine tracked as the named operation, replacing the
+// kms.Wait() + go func() { defer kms.Done() }() pattern.
+//
+// fn is passed a kms.NotifyContext(), which is cancelled once a shutdown is initiated; panics are
+// recovered and returned errors collected, see Errors().
+func Go(name string, fn func(ctx context.Context) error) {
+	defaultManager.spawn(defaultManager.track(name, nil, false), fn)
+}
+
+// Errors returns the errors, of type *GoError, collected from goroutines started using Go.
+func Errors() []error {
+	return defaultManager.Errors()
+}
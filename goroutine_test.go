@@ -0,0 +1,112 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGo(t *testing.T) {
+
+	m := New(WithSignalFn(chanSignalFn(make(chan os.Signal))))
+
+	errFailed := errors.New("failed")
+
+	m.Go("fails", func(ctx context.Context) error {
+		return errFailed
+	})
+
+	m.Go("panics", func(ctx context.Context) error {
+		panic("boom")
+	})
+
+	m.Go("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if ops := m.InFlight(); len(ops) == 0 || !strings.Contains(ops[len(ops)-1].Stack, "TestGo") {
+		t.Errorf("Expected the goroutine to be tracked from the caller of Go")
+	}
+
+	m.Listen(false)
+	m.Shutdown(nil)
+
+	select {
+	case <-m.ShutdownComplete():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected shutdown to wait for the goroutines")
+	}
+
+	errs := m.Errors()
+
+	if len(errs) != 2 {
+		t.Fatalf("Expected '%d' errors Got '%v'", 2, errs)
+	}
+
+	var ge *GoError
+
+	for _, err := range errs {
+		if !errors.As(err, &ge) || (ge.Name != "fails" && ge.Name != "panics") {
+			t.Errorf("Expected *GoError from 'fails' or 'panics' Got '%v'", err)
+		}
+	}
+}
+
+func TestGoShutdownOnError(t *testing.T) {
+
+	m := New(
+		WithSignalFn(chanSignalFn(make(chan os.Signal))),
+		WithShutdownOnError(true),
+	)
+
+	errFailed := errors.New("failed")
+
+	m.Listen(false)
+
+	m.Go("fails", func(ctx context.Context) error {
+		return errFailed
+	})
+
+	select {
+	case <-m.ShutdownComplete():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected error to initiate a shutdown")
+	}
+
+	var ge *GoError
+
+	if reason := m.ShutdownReason(); !errors.As(reason, &ge) || !errors.Is(reason, errFailed) {
+		t.Errorf("Expected '%v' Got '%v'", errFailed, reason)
+	}
+}
+
+func TestGoReset(t *testing.T) {
+
+	m := New(WithSignalFn(chanSignalFn(make(chan os.Signal))))
+
+	m.Go("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if err := m.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Listen(false)
+	m.Shutdown(nil)
+
+	select {
+	case <-m.ShutdownComplete():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the goroutine to be notified of the shutdown after a reset")
+	}
+
+	if errs := m.Errors(); len(errs) != 0 {
+		t.Errorf("Expected no errors Got '%v'", errs)
+	}
+}
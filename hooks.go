@@ -0,0 +1,240 @@
+package kms
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Phase represents an ordered stage of the shutdown process in which
+// shutdown hooks are run. Phases run one after another, in order, and all
+// hooks within the same Phase are run concurrently.
+type Phase uint8
+
+// Shutdown phases, in the order in which they are run
+const (
+	// PhasePreDrain hooks run as soon as draining starts, after any lame-duck period,
+	// eg. stop accepting new connections or work.
+	PhasePreDrain Phase = iota
+
+	// PhaseDrain hooks run while waiting for all in-flight operations to complete
+	// eg. flushing queues.
+	PhaseDrain
+
+	// PhaseClose hooks run once all in-flight operations have completed eg. closing
+	// database connection pools.
+	PhaseClose
+
+	// PhaseFinal hooks run just before the shutdown is complete eg. flushing logs.
+	PhaseFinal
+
+	numPhases
+)
+
+// String returns the name of the Phase
+func (p Phase) String() string {
+	switch p {
+	case PhasePreDrain:
+		return "PreDrain"
+	case PhaseDrain:
+		return "Drain"
+	case PhaseClose:
+		return "Close"
+	case PhaseFinal:
+		return "Final"
+	default:
+		return fmt.Sprintf("Phase(%d)", uint8(p))
+	}
+}
+
+// HookFunc is the function type run during a shutdown phase. The provided
+// context is cancelled once the hooks timeout expires and carries the shutdown
+// reason, see ReasonFromContext().
+type HookFunc func(ctx context.Context) error
+
+// HookOption configures a shutdown hook, see OnShutdown()
+type HookOption func(*hook)
+
+// HookTimeout sets the maximum amount of time the hook is allowed to run, it is always
+// capped at the remaining ListenTimeout budget.
+//
+// Default: the remaining ListenTimeout budget, or no timeout when using Listen
+func HookTimeout(timeout time.Duration) HookOption {
+	return func(h *hook) {
+		h.timeout = timeout
+	}
+}
+
+// HookError is the error recorded when a shutdown hook fails or exceeds it's timeout.
+type HookError struct {
+	Phase Phase
+	Name  string
+	Err   error
+}
+
+// Error returns the hook error's string representation
+func (e *HookError) Error() string {
+	return fmt.Sprintf("kms: %s hook %q: %s", e.Phase, e.Name, e.Err)
+}
+
+// Unwrap returns the underlying hook error
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+type hook struct {
+	id      uint64
+	name    string
+	fn      HookFunc
+	timeout time.Duration
+}
+
+// OnShutdown registers a hook to be run during the provided shutdown Phase.
+//
+// Hooks are guaranteed to be run in Phase order, hooks registered within the same Phase
+// run concurrently and any errors are collected, see HookErrors().
+func (m *Manager) OnShutdown(phase Phase, name string, fn HookFunc, opts ...HookOption) {
+
+	if phase >= numPhases {
+		panic(fmt.Sprintf("kms: invalid shutdown phase %s", phase))
+	}
+
+	m.mu.Lock()
+	m.hooks[phase] = append(m.hooks[phase], newHook(name, fn, opts))
+	m.mu.Unlock()
+}
+
+// addHook appends the hook to hooks returning a func removing it again, must be called
+// with mu held.
+func (m *Manager) addHook(hooks *[]hook, h hook) (unregister func()) {
+
+	m.hookID++
+	h.id = m.hookID
+	*hooks = append(*hooks, h)
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		for i := range *hooks {
+			if (*hooks)[i].id == h.id {
+				// copied so that hooks being run from a previous copy are unaffected
+				*hooks = append((*hooks)[:i:i], (*hooks)[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func newHook(name string, fn HookFunc, opts []HookOption) hook {
+
+	h := hook{
+		name: name,
+		fn:   fn,
+	}
+
+	for _, opt := range opts {
+		opt(&h)
+	}
+
+	return h
+}
+
+// HookErrors returns the errors, of type *HookError, collected from shutdown hooks.
+func (m *Manager) HookErrors() []error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]error(nil), m.hookErrs...)
+}
+
+// runPhase runs all hooks registered for the provided phase concurrently and
+// blocks until they have all returned or timed out.
+func (m *Manager) runPhase(ctx context.Context, phase Phase, deadline time.Time) {
+
+	m.mu.Lock()
+	hooks := append([]hook(nil), m.hooks[phase]...)
+	m.mu.Unlock()
+
+	m.runHooks(ctx, hooks, deadline, func(h hook, err error) {
+		m.Logger().Error("shutdown hook failed", "phase", phase, "hook", h.name, "error", err)
+		m.mu.Lock()
+		m.hookErrs = append(m.hookErrs, &HookError{Phase: phase, Name: h.name, Err: err})
+		m.mu.Unlock()
+	})
+}
+
+// runHooks runs the hooks concurrently, calling failed for each one returning an error, and
+// blocks until they have all returned or timed out.
+func (m *Manager) runHooks(ctx context.Context, hooks []hook, deadline time.Time, failed func(hook, error)) {
+
+	var wg sync.WaitGroup
+
+	for _, h := range hooks {
+
+		wg.Add(1)
+
+		go func(h hook) {
+			defer wg.Done()
+
+			if err := m.runHook(ctx, h, deadline); err != nil {
+				failed(h, err)
+			}
+		}(h)
+	}
+
+	wg.Wait()
+}
+
+func (m *Manager) runHook(parent context.Context, h hook, deadline time.Time) error {
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	if h.timeout > 0 {
+		if hd := m.Clock().Now().Add(h.timeout); deadline.IsZero() || hd.Before(deadline) {
+			deadline = hd
+		}
+	}
+
+	if !deadline.IsZero() {
+		ctx, cancel = m.withDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	result := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+
+		result <- h.fn(ctx)
+	}()
+
+	// a hook which does not respect it's context is abandoned once it's timeout has
+	// expired so that later phases are still run.
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OnShutdown registers a hook to be run during the provided shutdown Phase.
+//
+// Hooks are guaranteed to be run in Phase order, hooks registered within the same Phase
+// run concurrently and any errors are collected, see HookErrors().
+func OnShutdown(phase Phase, name string, fn HookFunc, opts ...HookOption) {
+	defaultManager.OnShutdown(phase, name, fn, opts...)
+}
+
+// HookErrors returns the errors, of type *HookError, collected from shutdown hooks.
+func HookErrors() []error {
+	return defaultManager.HookErrors()
+}
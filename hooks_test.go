@@ -0,0 +1,125 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestOnShutdownOrder(t *testing.T) {
+
+	sig := make(chan os.Signal, 1)
+	m := New(WithSignalFn(chanSignalFn(sig)))
+
+	var mu sync.Mutex
+	var order []string
+
+	record := func(name string) HookFunc {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	m.OnShutdown(PhaseFinal, "final", record("final"))
+	m.OnShutdown(PhaseClose, "close", record("close"))
+	m.OnShutdown(PhasePreDrain, "pre-drain", record("pre-drain"))
+
+	// in-flight operation must complete before PhaseClose
+	m.Wait()
+	m.OnShutdown(PhaseDrain, "drain", func(ctx context.Context) error {
+		record("drain")(ctx)
+		m.Done()
+		return nil
+	})
+
+	m.Listen(false)
+	sig <- syscall.SIGTERM
+
+	select {
+	case <-m.ShutdownComplete():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected shutdown to complete")
+	}
+
+	expected := []string{"pre-drain", "drain", "close", "final"}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(order) != len(expected) {
+		t.Fatalf("Expected '%v' Got '%v'", expected, order)
+	}
+
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Errorf("Expected '%v' Got '%v'", expected, order)
+		}
+	}
+
+	if errs := m.HookErrors(); len(errs) != 0 {
+		t.Errorf("Expected no hook errors Got '%v'", errs)
+	}
+}
+
+func TestOnShutdownErrorsAndTimeouts(t *testing.T) {
+
+	sig := make(chan os.Signal, 1)
+	m := New(WithSignalFn(chanSignalFn(sig)))
+
+	errFailed := errors.New("failed")
+
+	m.OnShutdown(PhaseClose, "fails", func(ctx context.Context) error {
+		return errFailed
+	})
+
+	m.OnShutdown(PhaseClose, "slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, HookTimeout(time.Millisecond*20))
+
+	m.OnShutdown(PhaseClose, "stuck", func(ctx context.Context) error {
+		select {}
+	}, HookTimeout(time.Millisecond*20))
+
+	m.ListenTimeout(false, time.Minute)
+	sig <- syscall.SIGTERM
+
+	select {
+	case <-m.ShutdownComplete():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected shutdown to complete")
+	}
+
+	errs := m.HookErrors()
+	if len(errs) != 3 {
+		t.Fatalf("Expected '%d' Got '%d'", 3, len(errs))
+	}
+
+	var failed, timedOut int
+
+	for _, err := range errs {
+
+		var he *HookError
+		if !errors.As(err, &he) || he.Phase != PhaseClose {
+			t.Errorf("Expected *HookError in PhaseClose Got '%v'", err)
+		}
+
+		switch {
+		case errors.Is(err, errFailed):
+			failed++
+		case errors.Is(err, context.DeadlineExceeded):
+			timedOut++
+		}
+	}
+
+	if failed != 1 || timedOut != 2 {
+		t.Errorf("Expected 1 failed and 2 timed out Got '%d' and '%d'", failed, timedOut)
+	}
+}
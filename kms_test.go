@@ -20,15 +20,18 @@ import (
 // go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
 //
 //
-// because 'kms' is a singleton testing required some variables to be reinitialized
+// because the package level functions use a singleton Manager testing required some variables to be reinitialized
 //
 
 func reinitialize() {
-	notify.Store(make(chan struct{}))
-	done.Store(make(chan struct{}))
+
+	if err := Reset(); err != nil {
+		panic(err)
+	}
+
 	AllowSignalHardShutdown(true)
 
-	exitFunc.Store(func(code int) {
+	defaultManager.exitFunc.Store(func(code int) {
 		fmt.Println("Exiting")
 	})
 }
@@ -156,9 +159,10 @@ func TestListenTimeoutTimeout(t *testing.T) {
 	reinitialize()
 	AllowSignalHardShutdown(false)
 
-	exitFunc.Store(func(code int) {
+	// complete the outstanding operation so the shutdown is not left draining for the next test
+	defaultManager.exitFunc.Store(func(code int) {
 		fmt.Println("Exiting OK")
-		close(done.Load().(chan struct{}))
+		Done()
 	})
 
 	m := sync.Mutex{}
@@ -199,11 +203,6 @@ func TestListenTimeoutDone(t *testing.T) {
 
 	reinitialize()
 
-	exitFunc.Store(func(code int) {
-		fmt.Println("Exiting OK")
-		close(done.Load().(chan struct{}))
-	})
-
 	m := sync.Mutex{}
 
 	var stopping, stopped bool
@@ -257,8 +256,6 @@ func TestListenTimeoutDoneNoHardShutdown(t *testing.T) {
 	go func() {
 		<-time.After(time.Second * 1)
 		syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
-		<-time.After(time.Second * 1)
-		close(done.Load().(chan struct{}))
 	}()
 
 	ListenTimeout(true, time.Second*10)
@@ -282,9 +279,10 @@ func TestListenTimeoutDoubleKill(t *testing.T) {
 
 	reinitialize()
 
-	exitFunc.Store(func(code int) {
+	// complete the outstanding operation so the shutdown is not left draining for the next test
+	defaultManager.exitFunc.Store(func(code int) {
 		fmt.Println("Exiting OK")
-		close(done.Load().(chan struct{}))
+		Done()
 	})
 
 	go func() {
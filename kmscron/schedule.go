@@ -0,0 +1,213 @@
+package kmscron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule determines when a job runs.
+type Schedule interface {
+	// Next returns the next time the job should run after the provided time, the zero
+	// time means the job never runs again.
+	Next(after time.Time) time.Time
+}
+
+// Every returns a Schedule running a job at a fixed interval, the first run being one
+// interval after the job is added.
+func Every(d time.Duration) Schedule {
+
+	if d <= 0 {
+		panic("kmscron: interval must be positive")
+	}
+
+	return every(d)
+}
+
+type every time.Duration
+
+func (e every) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
+
+// cron is a parsed 5 field cron expression, each field a bit set of the allowed values.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+
+	// when both day of month and day of week are restricted, neither starting with *, a day
+	// matching either runs; the same as Vixie cron.
+	domStar, dowStar bool
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = [...]field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Parse parses a standard 5 field cron expression; minute, hour, day of month, month and day of week.
+//
+// Each field accepts *, a value, ranges eg. 1-5, steps eg. */15 or 0-30/10, and comma separated lists
+// of these. Day of week is 0-7 where both 0 and 7 are Sunday. The shorthands @yearly, @monthly,
+// @weekly, @daily and @hourly are also accepted.
+//
+// Times are evaluated in the location of the time passed to Next.
+func Parse(expr string) (Schedule, error) {
+
+	switch expr {
+	case "@yearly", "@annually":
+		expr = "0 0 1 1 *"
+	case "@monthly":
+		expr = "0 0 1 * *"
+	case "@weekly":
+		expr = "0 0 * * 0"
+	case "@daily", "@midnight":
+		expr = "0 0 * * *"
+	case "@hourly":
+		expr = "0 * * * *"
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("kmscron: expected %d fields in %q, got %d", len(fields), expr, len(parts))
+	}
+
+	var sets [len(fields)]uint64
+
+	for i, part := range parts {
+
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("kmscron: parsing %q: %w", expr, err)
+		}
+
+		sets[i] = set
+	}
+
+	c := &cron{
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     sets[4],
+		domStar: strings.HasPrefix(parts[2], "*"),
+		dowStar: strings.HasPrefix(parts[4], "*"),
+	}
+
+	// 7 is also Sunday
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+
+	return c, nil
+}
+
+func parseField(s string, f field) (uint64, error) {
+
+	var set uint64
+
+	for _, item := range strings.Split(s, ",") {
+
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+
+		step := 1
+
+		if hasStep {
+
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", f.name, stepStr)
+			}
+
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+
+		if rng != "*" {
+
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+
+			n, err := strconv.Atoi(loStr)
+			if err != nil {
+				return 0, fmt.Errorf("invalid %s %q", f.name, item)
+			}
+
+			lo, hi = n, n
+
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return 0, fmt.Errorf("invalid %s %q", f.name, item)
+				}
+			} else if hasStep {
+				// a single value with a step runs from the value to the max eg. 5/15
+				hi = f.max
+			}
+		}
+
+		if lo < f.min || hi > f.max || lo > hi {
+			return 0, fmt.Errorf("%s %q out of range %d-%d", f.name, item, f.min, f.max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+
+	return set, nil
+}
+
+// Next returns the first minute after the provided time matching the expression, searching
+// up to 5 years ahead.
+func (c *cron) Next(after time.Time) time.Time {
+
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (c *cron) dayMatches(t time.Time) bool {
+
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
@@ -0,0 +1,55 @@
+package kmscron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+
+	// a Wednesday
+	after := time.Date(2024, 1, 10, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 10, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 10, 10, 45, 0, 0, time.UTC)},
+		{"0 6 * * *", time.Date(2024, 1, 11, 6, 0, 0, 0, time.UTC)},
+		{"0 6 * * 1-5", time.Date(2024, 1, 11, 6, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{"30 9 1,15 * *", time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)}, // day of month or Friday
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Expected '%s' to parse Got '%v'", tt.expr, err)
+		}
+
+		if next := s.Next(after); !next.Equal(tt.expected) {
+			t.Errorf("Expected '%s' next '%s' Got '%s'", tt.expr, tt.expected, next)
+		}
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Expected '%s' to fail to parse", expr)
+		}
+	}
+}
+
+func TestEvery(t *testing.T) {
+
+	now := time.Now()
+
+	if next := Every(time.Minute).Next(now); !next.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected '%s' Got '%s'", now.Add(time.Minute), next)
+	}
+}
@@ -0,0 +1,232 @@
+// Package kmscron runs periodic jobs which respect the kms lifecycle; no new run is started once
+// a shutdown has been initiated and running jobs are tracked as in-flight operations, so the
+// shutdown waits for them to complete.
+//
+//	s := kmscron.New()
+//
+//	s.Add("cleanup", kmscron.Every(time.Minute), func(ctx context.Context) error {
+//		return db.DeleteExpired(ctx)
+//	})
+//
+//	report, _ := kmscron.Parse("0 6 * * 1-5")
+//
+//	s.Add("report", report, sendReport, kmscron.WithOverlap(kmscron.OverlapQueue))
+package kmscron
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-playground/kms"
+)
+
+// OverlapPolicy determines what happens when a job is due while a previous run is still running.
+type OverlapPolicy uint8
+
+// Overlap policies
+const (
+	// OverlapSkip skips the run.
+	OverlapSkip OverlapPolicy = iota
+
+	// OverlapQueue runs the job again once the previous run completes; multiple runs queued
+	// while the job is running are coalesced into one.
+	OverlapQueue
+
+	// OverlapAllow runs the job concurrently with the previous run.
+	OverlapAllow
+)
+
+// String returns the name of the OverlapPolicy
+func (p OverlapPolicy) String() string {
+	switch p {
+	case OverlapSkip:
+		return "skip"
+	case OverlapQueue:
+		return "queue"
+	case OverlapAllow:
+		return "allow"
+	default:
+		return fmt.Sprintf("OverlapPolicy(%d)", uint8(p))
+	}
+}
+
+// JobOption configures a job, see Scheduler.Add()
+type JobOption func(*job)
+
+// WithOverlap sets the job's OverlapPolicy.
+//
+// Default: OverlapSkip
+func WithOverlap(p OverlapPolicy) JobOption {
+	return func(j *job) {
+		j.overlap = p
+	}
+}
+
+// Scheduler runs jobs on their Schedule until a shutdown of it's kms.Manager is initiated.
+type Scheduler struct {
+	m *kms.Manager
+}
+
+// New returns a new Scheduler tied to the lifecycle of the package level kms functions.
+func New() *Scheduler {
+	return NewWithManager(kms.Default())
+}
+
+// NewWithManager returns a new Scheduler tied to the lifecycle of the provided kms.Manager.
+func NewWithManager(m *kms.Manager) *Scheduler {
+	return &Scheduler{m: m}
+}
+
+type job struct {
+	s        *Scheduler
+	name     string
+	schedule Schedule
+	fn       func(ctx context.Context) error
+	overlap  OverlapPolicy
+
+	mu      sync.Mutex
+	running int
+	queued  bool
+}
+
+// Add schedules fn to run according to schedule, starting immediately. Each run is tracked as a
+// kms operation, named "kmscron.job" labelled with the job name, and passed the
+// kms.HardStopContext() so that it may finish gracefully during the shutdown but must unwind
+// before the process exits.
+//
+// Errors and panics are logged using the kms.Manager's logger.
+func (s *Scheduler) Add(name string, schedule Schedule, fn func(ctx context.Context) error, opts ...JobOption) {
+
+	j := &job{
+		s:        s,
+		name:     name,
+		schedule: schedule,
+		fn:       fn,
+	}
+
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	go j.loop()
+}
+
+func (j *job) loop() {
+
+	m := j.s.m
+	clock := m.Clock()
+
+	// follows the Manager across a Reset, the job stops at the first shutdown after it was added.
+	ctx, stop := m.NotifyContext(context.Background())
+	defer stop()
+
+	for {
+
+		now := clock.Now()
+		next := j.schedule.Next(now)
+
+		if next.IsZero() {
+			return
+		}
+
+		t := clock.NewTimer(next.Sub(now))
+
+		select {
+		case <-t.C():
+			j.due()
+		case <-ctx.Done():
+			t.Stop()
+			return
+		}
+	}
+}
+
+// due runs the job according to it's OverlapPolicy.
+func (j *job) due() {
+
+	j.mu.Lock()
+
+	if j.running > 0 {
+		switch j.overlap {
+		case OverlapSkip:
+			j.mu.Unlock()
+			j.s.m.Logger().Warn("kmscron: job still running, skipping run", "job", j.name)
+			return
+		case OverlapQueue:
+			j.queued = true
+			j.mu.Unlock()
+			return
+		}
+	}
+
+	j.mu.Unlock()
+
+	j.start()
+}
+
+// start runs the job in a new goroutine unless a shutdown has been initiated.
+func (j *job) start() {
+
+	m := j.s.m
+
+	// tracked before checking for the shutdown, either the run is rejected or the shutdown
+	// waits for it.
+	op := m.Track("kmscron.job", "job", j.name)
+
+	select {
+	case <-m.ShutdownInitiated():
+		op.Done()
+		return
+	default:
+	}
+
+	j.mu.Lock()
+	j.running++
+	j.mu.Unlock()
+
+	go func() {
+		defer op.Done()
+
+		for {
+			j.run()
+
+			j.mu.Lock()
+
+			again := j.queued
+			j.queued = false
+
+			if !again {
+				j.running--
+				j.mu.Unlock()
+				return
+			}
+
+			j.mu.Unlock()
+
+			select {
+			case <-m.ShutdownInitiated():
+				j.mu.Lock()
+				j.running--
+				j.mu.Unlock()
+				return
+			default:
+			}
+		}
+	}()
+}
+
+func (j *job) run() {
+
+	m := j.s.m
+
+	defer func() {
+		if r := recover(); r != nil {
+			m.Logger().Error("kmscron: job panicked", "job", j.name, "panic", fmt.Sprint(r))
+		}
+	}()
+
+	if err := j.fn(m.HardStopContext()); err != nil {
+		m.Logger().Error("kmscron: job failed", "job", j.name, "error", err)
+	}
+}
@@ -0,0 +1,162 @@
+package kmscron
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/kms/kmstest"
+)
+
+func TestScheduler(t *testing.T) {
+
+	h := kmstest.New()
+	s := NewWithManager(h.Manager)
+
+	runs := make(chan context.Context)
+	release := make(chan struct{})
+
+	s.Add("job", Every(time.Minute), func(ctx context.Context) error {
+		runs <- ctx
+		<-release
+		return nil
+	})
+
+	h.Clock.BlockUntil(1)
+	h.Clock.Advance(time.Minute)
+
+	ctx := <-runs
+
+	if ctx != h.HardStopContext() {
+		t.Errorf("Expected the job to be passed the hard stop context")
+	}
+
+	if n := h.InFlightCount(); n != 1 {
+		t.Errorf("Expected '%d' in-flight Got '%d'", 1, n)
+	}
+
+	// overlapping run is skipped by default
+	h.Clock.BlockUntil(1)
+	h.Clock.Advance(time.Minute)
+	h.Clock.BlockUntil(1)
+
+	select {
+	case <-runs:
+		t.Fatalf("Expected overlapping run to be skipped")
+	default:
+	}
+
+	h.Listen(false)
+	h.Shutdown(nil)
+	<-h.ShutdownInitiated()
+
+	// no new runs once shutdown is initiated, the running one is waited on
+	h.Clock.Advance(time.Minute)
+
+	select {
+	case <-h.ShutdownComplete():
+		t.Fatalf("Expected shutdown to wait for the running job")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	close(release)
+
+	kmstest.AssertDrainedWithin(t, h.Manager, time.Second)
+
+	select {
+	case <-runs:
+		t.Fatalf("Expected no run after shutdown was initiated")
+	default:
+	}
+}
+
+func TestSchedulerOverlap(t *testing.T) {
+
+	for _, tt := range []struct {
+		overlap  OverlapPolicy
+		expected int
+	}{
+		{OverlapSkip, 1},
+		{OverlapQueue, 2},
+		{OverlapAllow, 3},
+	} {
+		t.Run(tt.overlap.String(), func(t *testing.T) {
+
+			h := kmstest.New()
+			s := NewWithManager(h.Manager)
+
+			started := make(chan struct{}, 3)
+			release := make(chan struct{})
+
+			s.Add("job", Every(time.Minute), func(ctx context.Context) error {
+				started <- struct{}{}
+				<-release
+				return nil
+			}, WithOverlap(tt.overlap))
+
+			h.Clock.BlockUntil(1)
+			h.Clock.Advance(time.Minute)
+			<-started
+
+			// two more runs are due while the first is still running
+			for i := 0; i < 2; i++ {
+				h.Clock.BlockUntil(1)
+				h.Clock.Advance(time.Minute)
+			}
+
+			h.Clock.BlockUntil(1)
+			close(release)
+
+			h.Listen(false)
+
+			// let queued runs start before initiating the shutdown
+			deadline := time.After(time.Second)
+
+			for n := 1; n < tt.expected; n++ {
+				select {
+				case <-started:
+				case <-deadline:
+					t.Fatalf("Expected '%d' runs Got '%d'", tt.expected, n)
+				}
+			}
+
+			h.Shutdown(nil)
+			kmstest.AssertDrainedWithin(t, h.Manager, time.Second)
+
+			if len(started) != 0 {
+				t.Errorf("Expected '%d' runs Got '%d'", tt.expected, tt.expected+len(started))
+			}
+		})
+	}
+}
+
+func TestSchedulerReset(t *testing.T) {
+
+	h := kmstest.New()
+	s := NewWithManager(h.Manager)
+
+	s.Add("job", Every(time.Minute), func(ctx context.Context) error {
+		return nil
+	})
+
+	h.Clock.BlockUntil(1)
+
+	if err := h.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	h.Listen(false)
+	h.Shutdown(nil)
+	kmstest.AssertDrainedWithin(t, h.Manager, time.Second)
+
+	// the job stops waiting for it's next run at the first shutdown after a reset
+	deadline := time.After(time.Second)
+
+	for h.Clock.Timers() != 0 {
+		select {
+		case <-deadline:
+			t.Fatalf("Expected the job to stop Got '%d' timers", h.Clock.Timers())
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,117 @@
+//go:build unix
+
+package kmsnet
+
+import (
+	"fmt"
+	stdnet "net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/go-playground/kms"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd, SD_LISTEN_FDS_START
+var listenFDsStart = 3
+
+// ActivatedListeners returns the listeners passed to the process by systemd socket activation,
+// pre-wired with notification and shutdown signals, keyed by their name as set by
+// FileDescriptorName= in the .socket unit; unnamed sockets are keyed as "unknown".
+//
+// eg. kmshttp.Serve(listeners["http"][0], nil)
+//
+// nil is returned when the process was not socket activated. The LISTEN_PID, LISTEN_FDS
+// and LISTEN_FDNAMES environment variables are removed so they are not passed on to
+// child processes.
+func ActivatedListeners() (map[string][]stdnet.Listener, error) {
+	return ActivatedListenersWithManager(kms.Default())
+}
+
+// ActivatedListenersWithManager acts identically to ActivatedListeners, except that the
+// listeners are pre-wired with the provided kms.Manager.
+func ActivatedListenersWithManager(m *kms.Manager) (map[string][]stdnet.Listener, error) {
+
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+
+	var names []string
+
+	if v := os.Getenv("LISTEN_FDNAMES"); v != "" {
+		names = strings.Split(v, ":")
+	}
+
+	listeners := make(map[string][]stdnet.Listener)
+
+	for i := 0; i < n; i++ {
+
+		fd := listenFDsStart + i
+		syscall.CloseOnExec(fd)
+
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		l, err := activatedListener(m, fd, name)
+		if err != nil {
+
+			for _, ls := range listeners {
+				for _, l := range ls {
+					l.Close()
+				}
+			}
+
+			return nil, fmt.Errorf("kmsnet: activated socket %q (fd %d): %w", name, fd, err)
+		}
+
+		listeners[name] = append(listeners[name], l)
+	}
+
+	return listeners, nil
+}
+
+func activatedListener(m *kms.Manager, fd int, name string) (stdnet.Listener, error) {
+
+	f := os.NewFile(uintptr(fd), name)
+
+	// net.FileListener dup's the fd
+	l, err := stdnet.FileListener(f)
+	f.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := l.(type) {
+	case *stdnet.TCPListener:
+		register(m, t.Addr().Network(), t.Addr().String(), t)
+		wl := wrapTCP(m, t)
+		closeOnShutdown(m, wl)
+		return wl, nil
+
+	case *stdnet.UnixListener:
+		register(m, t.Addr().Network(), t.Addr().String(), t)
+		wl := wrapUnix(m, t)
+		closeOnShutdown(m, wl)
+		return wl, nil
+
+	default:
+		l.Close()
+		return nil, fmt.Errorf("unsupported listener type %T", l)
+	}
+}
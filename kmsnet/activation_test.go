@@ -0,0 +1,112 @@
+//go:build unix
+
+package kmsnet
+
+import (
+	"fmt"
+	stdnet "net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/go-playground/kms"
+)
+
+func TestActivatedListeners(t *testing.T) {
+
+	tl, err := stdnet.ListenTCP("tcp", &stdnet.TCPAddr{IP: stdnet.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	ul, err := stdnet.ListenUnix("unix", &stdnet.UnixAddr{Name: filepath.Join(t.TempDir(), "kms.sock"), Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ul.Close()
+
+	// hand the sockets over at consecutive fds, as systemd would, out of the way of
+	// any fds in use by the test process
+	start := 100
+
+	for i, l := range []interface{ File() (*os.File, error) }{tl, ul} {
+
+		f, err := l.File()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = syscall.Dup2(int(f.Fd()), start+i); err != nil {
+			t.Fatal(err)
+		}
+
+		f.Close()
+	}
+
+	defer func(orig int) { listenFDsStart = orig }(listenFDsStart)
+	listenFDsStart = start
+
+	os.Setenv("LISTEN_PID", fmt.Sprint(os.Getpid()))
+	os.Setenv("LISTEN_FDS", "2")
+	os.Setenv("LISTEN_FDNAMES", "http:admin")
+
+	m := kms.New()
+
+	listeners, err := ActivatedListenersWithManager(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if os.Getenv("LISTEN_FDS") != "" {
+		t.Errorf("Expected activation environment to be cleared")
+	}
+
+	if len(listeners["http"]) != 1 || len(listeners["admin"]) != 1 {
+		t.Fatalf("Expected named listeners Got '%v'", listeners)
+	}
+
+	if addr := listeners["http"][0].Addr().String(); addr != tl.Addr().String() {
+		t.Errorf("Expected '%s' Got '%s'", tl.Addr().String(), addr)
+	}
+
+	if addr := listeners["admin"][0].Addr().String(); addr != ul.Addr().String() {
+		t.Errorf("Expected '%s' Got '%s'", ul.Addr().String(), addr)
+	}
+
+	// already wired listeners are accepted by kmshttp.Serve
+	if l, err := Wrap(m, listeners["http"][0]); err != nil || l != listeners["http"][0] {
+		t.Errorf("Expected activated listener to be returned as is Got '%v'", err)
+	}
+
+	go func() {
+		conn, err := stdnet.Dial("tcp", tl.Addr().String())
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	conn, err := listeners["http"][0].Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	for _, ls := range listeners {
+		for _, l := range ls {
+			l.Close()
+		}
+	}
+}
+
+func TestActivatedListenersNotActivated(t *testing.T) {
+
+	os.Setenv("LISTEN_PID", fmt.Sprint(os.Getpid()+1))
+	os.Setenv("LISTEN_FDS", "1")
+
+	listeners, err := ActivatedListeners()
+	if err != nil || listeners != nil {
+		t.Errorf("Expected no listeners for another process Got '%v' '%v'", listeners, err)
+	}
+}
@@ -0,0 +1,96 @@
+package kmshttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/kms"
+)
+
+// healthStatus is the JSON body written by the health handlers
+type healthStatus struct {
+	Status      string   `json:"status"`
+	State       string   `json:"state"`
+	InFlight    int      `json:"in_flight"`
+	ShutdownFor string   `json:"shutdown_for,omitempty"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// ReadinessHandler returns an http.Handler reporting 200 while the process is running and all
+// readiness checks have passed, see kms.AddReadinessCheck(), and 503 once a shutdown has been
+// initiated, including during the lame-duck period.
+//
+// The JSON body describes the lifecycle state, in-flight operation count and time since the
+// shutdown was initiated.
+func ReadinessHandler() http.Handler {
+	return ReadinessHandlerWithManager(kms.Default())
+}
+
+// ReadinessHandlerWithManager acts identically to ReadinessHandler, except that it reports
+// the readiness of the provided kms.Manager.
+func ReadinessHandlerWithManager(m *kms.Manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var errs []string
+
+		if err := m.CheckReadiness(r.Context()); err != nil {
+
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				for _, e := range joined.Unwrap() {
+					errs = append(errs, e.Error())
+				}
+			} else {
+				errs = append(errs, err.Error())
+			}
+		}
+
+		writeHealth(w, m, len(errs) == 0, errs)
+	})
+}
+
+// LivenessHandler returns an http.Handler reporting 200 until the shutdown has completed and 503
+// afterwards; the process remains live while draining so that it is not killed prematurely.
+//
+// The JSON body describes the lifecycle state, in-flight operation count and time since the
+// shutdown was initiated.
+func LivenessHandler() http.Handler {
+	return LivenessHandlerWithManager(kms.Default())
+}
+
+// LivenessHandlerWithManager acts identically to LivenessHandler, except that it reports
+// the liveness of the provided kms.Manager.
+func LivenessHandlerWithManager(m *kms.Manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, m, m.State() != kms.StateStopped, nil)
+	})
+}
+
+func writeHealth(w http.ResponseWriter, m *kms.Manager, ok bool, errs []string) {
+
+	status := healthStatus{
+		Status:   "ok",
+		State:    m.State().String(),
+		InFlight: m.InFlightCount(),
+		Errors:   errs,
+	}
+
+	if started, shutdown := m.ShutdownStarted(); shutdown {
+		status.ShutdownFor = m.Clock().Now().Sub(started).Round(time.Millisecond).String()
+	}
+
+	code := http.StatusOK
+
+	if !ok {
+		status.Status = "unavailable"
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		m.Logger().Error("kmshttp: writing health status", "error", err)
+	}
+}
@@ -0,0 +1,84 @@
+package kmshttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-playground/kms"
+)
+
+func getHealth(t *testing.T, h http.Handler) (int, healthStatus) {
+
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var status healthStatus
+
+	if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+
+	return w.Code, status
+}
+
+func TestHealthHandlers(t *testing.T) {
+
+	m := kms.New(
+		kms.WithSignalFn(func() <-chan os.Signal { return make(chan os.Signal) }),
+		kms.WithLameDuck(time.Millisecond*50),
+	)
+
+	ready := false
+
+	m.AddReadinessCheck("warm", func(ctx context.Context) error {
+		if !ready {
+			return errors.New("cold")
+		}
+		return nil
+	})
+
+	readiness := ReadinessHandlerWithManager(m)
+	liveness := LivenessHandlerWithManager(m)
+
+	code, status := getHealth(t, readiness)
+	if code != http.StatusServiceUnavailable || len(status.Errors) != 1 {
+		t.Errorf("Expected '%d' with failed check Got '%d' '%v'", http.StatusServiceUnavailable, code, status)
+	}
+
+	ready = true
+
+	code, status = getHealth(t, readiness)
+	if code != http.StatusOK || status.State != "running" {
+		t.Errorf("Expected '%d' Got '%d' '%v'", http.StatusOK, code, status)
+	}
+
+	op := m.Track("request")
+
+	m.Listen(false)
+	m.Shutdown(nil)
+	<-m.ShutdownInitiated()
+
+	code, status = getHealth(t, readiness)
+	if code != http.StatusServiceUnavailable || status.State != "lame-duck" || status.InFlight != 1 || status.ShutdownFor == "" {
+		t.Errorf("Expected '%d' during lame-duck Got '%d' '%v'", http.StatusServiceUnavailable, code, status)
+	}
+
+	if code, _ = getHealth(t, liveness); code != http.StatusOK {
+		t.Errorf("Expected '%d' Got '%d'", http.StatusOK, code)
+	}
+
+	op.Done()
+	<-m.ShutdownComplete()
+
+	if code, _ = getHealth(t, liveness); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected '%d' Got '%d'", http.StatusServiceUnavailable, code)
+	}
+}
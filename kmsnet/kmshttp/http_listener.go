@@ -15,38 +15,82 @@ const (
 	http11            = "http/1.1"
 )
 
+type serveOptions struct {
+	m         *kms.Manager
+	listening []func(l net.Listener)
+}
+
+// ServeOption configures ListenAndServe, ListenAndServeTLS and RunServer.
+type ServeOption func(*serveOptions)
+
+// OnListening registers fn to be called with the listener once it has been created, just
+// before the server starts serving.
+func OnListening(fn func(l net.Listener)) ServeOption {
+	return func(o *serveOptions) {
+		o.listening = append(o.listening, fn)
+	}
+}
+
+// UpgradeReady calls kmsnet.Ready() once the server's listener has been created, signalling
+// the parent process of a kmsnet.Upgrade() that it may start it's graceful shutdown.
+//
+// only use it when this is the process' last listener to be created.
+func UpgradeReady() ServeOption {
+	return func(o *serveOptions) {
+		m := o.m
+		o.listening = append(o.listening, func(l net.Listener) {
+			if err := kmsnet.Ready(); err != nil {
+				m.Logger().Error("kmshttp: signalling upgrade readiness", "addr", l.Addr().String(), "error", err)
+			}
+		})
+	}
+}
+
+// listening calls the OnListening functions once the server's listener has been created.
+func listening(m *kms.Manager, l net.Listener, opts []ServeOption) {
+
+	o := &serveOptions{m: m}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	for _, fn := range o.listening {
+		fn(l)
+	}
+}
+
 // ListenAndServe listens on the TCP network address addr and then calls Serve with handler to handle requests
 // on incoming connections. Accepted connections are configured to enable TCP keep-alives. Handler is typically
 // nil, in which case the DefaultServeMux is used.
-func ListenAndServe(addr string, handler http.Handler) (err error) {
+func ListenAndServe(addr string, handler http.Handler, opts ...ServeOption) (err error) {
+	return ListenAndServeWithManager(kms.Default(), addr, handler, opts...)
+}
+
+// ListenAndServeWithManager acts identically to ListenAndServe, except that the server
+// is tied to the lifecycle of the provided kms.Manager.
+func ListenAndServeWithManager(m *kms.Manager, addr string, handler http.Handler, opts ...ServeOption) (err error) {
 
 	if handler == nil {
 		handler = http.DefaultServeMux
 	}
 
-	l, err := kmsnet.NewTCPListenerNoShutdown("tcp", addr)
+	l, err := kmsnet.NewTCPListenerNoShutdownWithManager(m, "tcp", addr)
 	if err != nil {
 		return err
 	}
 
 	s := &http.Server{Addr: l.Addr().String(), Handler: handler}
 
-	server := &serverConnState{
-		Server:    s,
-		l:         l,
-		idleConns: make(map[net.Conn]struct{}),
-		active:    make(chan net.Conn),
-		idle:      make(chan net.Conn),
-		closed:    make(chan net.Conn),
-		shutdown:  make(chan struct{}),
-	}
+	listening(m, l, opts)
 
+	server := newServerConnState(m, s, l)
 	server.handleConnState()
 
 	err = server.Serve(server.l)
 
 	// wait for process shutdown to complete or timeout.
-	<-kms.ShutdownComplete()
+	<-m.ShutdownComplete()
 
 	return err
 }
@@ -54,24 +98,41 @@ func ListenAndServe(addr string, handler http.Handler) (err error) {
 // ListenAndServeTLS acts identically to ListenAndServe, except that it expects HTTPS connections. Additionally,
 // files containing a certificate and matching private key for the server must be provided. If the certificate is signed
 // by a certificate authority, the certFile should be the concatenation of the server's certificate, any intermediates,
-// and the CA's certificate.
-func ListenAndServeTLS(addr, certFile, keyFile string, handler http.Handler) (err error) {
+// and the CA's certificate. The files are re-read whenever kms reloads, see kms.OnReload()
+//
+// NOTE: registering the reload handler means that, while serving, the reload signal, SIGHUP by
+// default, reloads the certificate rather than initiating a shutdown; see kms.SetReloadSignal()
+// to use another signal or nil to disable reloading by signal.
+func ListenAndServeTLS(addr, certFile, keyFile string, handler http.Handler, opts ...ServeOption) (err error) {
+	return ListenAndServeTLSWithManager(kms.Default(), addr, certFile, keyFile, handler, opts...)
+}
 
-	tlsConfig := &tls.Config{
-		NextProtos:   []string{http2NextProtoTLS, http2Rev14, http11},
-		Certificates: make([]tls.Certificate, 1),
-	}
+// ListenAndServeTLSWithManager acts identically to ListenAndServeTLS, except that the server
+// is tied to the lifecycle of the provided kms.Manager.
+//
+// The certificate and key files are re-read whenever the kms.Manager reloads, see kms.OnReload()
+//
+// NOTE: registering the reload handler means that, while serving, the kms.Manager's reload signal,
+// SIGHUP by default, reloads the certificate rather than initiating a shutdown; see
+// kms.Manager.SetReloadSignal() to use another signal or nil to disable reloading by signal.
+func ListenAndServeTLSWithManager(m *kms.Manager, addr, certFile, keyFile string, handler http.Handler, opts ...ServeOption) (err error) {
 
-	tlsConfig.Certificates[0], err = tls.LoadX509KeyPair(certFile, keyFile)
+	certs, err := kmsnet.NewCertificateReloader(m, certFile, keyFile)
 	if err != nil {
 		return
 	}
+	defer certs.Close()
+
+	tlsConfig := &tls.Config{
+		NextProtos:     []string{http2NextProtoTLS, http2Rev14, http11},
+		GetCertificate: certs.GetCertificate,
+	}
 
 	if handler == nil {
 		handler = http.DefaultServeMux
 	}
 
-	l, err := kmsnet.NewTCPListenerNoShutdown("tcp", addr)
+	l, err := kmsnet.NewTCPListenerNoShutdownWithManager(m, "tcp", addr)
 	if err != nil {
 		return err
 	}
@@ -80,22 +141,15 @@ func ListenAndServeTLS(addr, certFile, keyFile string, handler http.Handler) (er
 
 	s := &http.Server{Addr: tlsListener.Addr().String(), Handler: handler, TLSConfig: tlsConfig}
 
-	server := &serverConnState{
-		Server:    s,
-		l:         tlsListener,
-		idleConns: make(map[net.Conn]struct{}),
-		active:    make(chan net.Conn),
-		idle:      make(chan net.Conn),
-		closed:    make(chan net.Conn),
-		shutdown:  make(chan struct{}),
-	}
+	listening(m, tlsListener, opts)
 
+	server := newServerConnState(m, s, tlsListener)
 	server.handleConnState()
 
 	err = server.Serve(server.l)
 
 	// wait for process shutdown to complete or timeout.
-	<-kms.ShutdownComplete()
+	<-m.ShutdownComplete()
 
 	return err
 }
@@ -105,8 +159,15 @@ func ListenAndServeTLS(addr, certFile, keyFile string, handler http.Handler) (er
 // read requests and then call handler to reply to them.
 // Handler is typically nil, in which case the DefaultServeMux is used.
 //
-// currently only net.TCPListener and net.UnixListener is supported
+// currently only net.TCPListener, net.UnixListener and listeners created by kmsnet,
+// such as those returned by kmsnet.ActivatedListeners(), are supported
 func Serve(l net.Listener, handler http.Handler) (err error) {
+	return ServeWithManager(kms.Default(), l, handler)
+}
+
+// ServeWithManager acts identically to Serve, except that the server
+// is tied to the lifecycle of the provided kms.Manager.
+func ServeWithManager(m *kms.Manager, l net.Listener, handler http.Handler) (err error) {
 
 	if handler == nil {
 		handler = http.DefaultServeMux
@@ -114,41 +175,32 @@ func Serve(l net.Listener, handler http.Handler) (err error) {
 
 	s := &http.Server{Handler: handler}
 
-	var lis net.Listener
-
-	switch l.(type) {
-	case *net.TCPListener:
-		lis = kmsnet.NewTCPNoShutdown(l.(*net.TCPListener))
-	case *net.UnixListener:
-		lis = kmsnet.NewUnixNoShutdown(l.(*net.UnixListener))
-	default:
-		panic("unsupported listener type")
-	}
-
-	server := &serverConnState{
-		Server:    s,
-		l:         lis,
-		idleConns: make(map[net.Conn]struct{}),
-		active:    make(chan net.Conn),
-		idle:      make(chan net.Conn),
-		closed:    make(chan net.Conn),
-		shutdown:  make(chan struct{}),
+	lis, err := kmsnet.Wrap(m, l)
+	if err != nil {
+		panic(err)
 	}
 
+	server := newServerConnState(m, s, lis)
 	server.handleConnState()
 
 	err = server.Serve(server.l)
 
 	// wait for process shutdown to complete or timeout.
-	<-kms.ShutdownComplete()
+	<-m.ShutdownComplete()
 
 	return err
 }
 
 // RunServer wraps an runs the given http.Server instance
-func RunServer(s *http.Server) (err error) {
+func RunServer(s *http.Server, opts ...ServeOption) (err error) {
+	return RunServerWithManager(kms.Default(), s, opts...)
+}
 
-	l, err := kmsnet.NewTCPListenerNoShutdown("tcp", s.Addr)
+// RunServerWithManager acts identically to RunServer, except that the server
+// is tied to the lifecycle of the provided kms.Manager.
+func RunServerWithManager(m *kms.Manager, s *http.Server, opts ...ServeOption) (err error) {
+
+	l, err := kmsnet.NewTCPListenerNoShutdownWithManager(m, "tcp", s.Addr)
 	if err != nil {
 		return err
 	}
@@ -163,28 +215,37 @@ func RunServer(s *http.Server) (err error) {
 		l = tls.NewListener(l, s.TLSConfig)
 	}
 
-	server := &serverConnState{
-		Server:    s,
-		l:         l,
-		idleConns: make(map[net.Conn]struct{}),
-		active:    make(chan net.Conn),
-		idle:      make(chan net.Conn),
-		closed:    make(chan net.Conn),
-		shutdown:  make(chan struct{}),
-	}
+	listening(m, l, opts)
 
+	server := newServerConnState(m, s, l)
 	server.handleConnState()
 
 	err = server.Serve(server.l)
 
 	// wait for process shutdown to complete or timeout.
-	<-kms.ShutdownComplete()
+	<-m.ShutdownComplete()
 
 	return err
 }
 
+// lameDuckHandler asks clients to close their connection once a shutdown has been initiated,
+// so that during the lame-duck period they reconnect elsewhere.
+func lameDuckHandler(m *kms.Manager, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		select {
+		case <-m.ShutdownInitiated():
+			w.Header().Set("Connection", "close")
+		default:
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
 type serverConnState struct {
 	*http.Server
+	m         *kms.Manager
 	l         net.Listener
 	idleConns map[net.Conn]struct{}
 	active    chan net.Conn
@@ -193,6 +254,26 @@ type serverConnState struct {
 	shutdown  chan struct{}
 }
 
+func newServerConnState(m *kms.Manager, s *http.Server, l net.Listener) *serverConnState {
+
+	if s.Handler == nil {
+		s.Handler = http.DefaultServeMux
+	}
+
+	s.Handler = lameDuckHandler(m, s.Handler)
+
+	return &serverConnState{
+		Server:    s,
+		m:         m,
+		l:         l,
+		idleConns: make(map[net.Conn]struct{}),
+		active:    make(chan net.Conn),
+		idle:      make(chan net.Conn),
+		closed:    make(chan net.Conn),
+		shutdown:  make(chan struct{}),
+	}
+}
+
 func (s *serverConnState) handleConnState() {
 
 	// we do not listen for hijacked, they are a lost cause at this level
@@ -266,8 +347,9 @@ func (s *serverConnState) handleConnState() {
 		}
 	}()
 
+	// listeners continue to accept connections during the lame-duck period
 	go func() {
-		<-kms.ShutdownInitiated()
+		<-s.m.DrainStarted()
 		s.shutdown <- struct{}{}
 	}()
 }
@@ -0,0 +1,64 @@
+package kmshttp
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-playground/kms"
+)
+
+func TestListenAndServeOnListening(t *testing.T) {
+
+	m := kms.New(
+		kms.WithSignalFn(func() <-chan os.Signal { return make(chan os.Signal) }),
+	)
+	m.Listen(false)
+
+	addrs := make(chan string, 1)
+	served := make(chan error, 1)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	go func() {
+		served <- ListenAndServeWithManager(m, "127.0.0.1:0", handler, OnListening(func(l net.Listener) {
+			addrs <- l.Addr().String()
+		}))
+	}()
+
+	var addr string
+
+	select {
+	case addr = <-addrs:
+	case err := <-served:
+		t.Fatalf("Expected OnListening to be called Got '%v'", err)
+	case <-time.After(time.Second * 5):
+		t.Fatalf("Expected OnListening to be called")
+	}
+
+	// the listener exists once OnListening is called, connections queue until served
+	resp, err := http.Get("http://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(b) != "ok" {
+		t.Errorf("Expected '%s' Got '%s'", "ok", b)
+	}
+
+	m.Shutdown(nil)
+
+	select {
+	case <-served:
+	case <-time.After(time.Second * 5):
+		t.Fatalf("Expected the server to return once shutdown")
+	}
+}
@@ -0,0 +1,124 @@
+package kmsnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	stdnet "net"
+	"sync"
+
+	"github.com/go-playground/kms"
+)
+
+// closeOnShutdown closes the listener once the kms.Manager starts draining, listeners
+// continue to accept connections during the lame-duck period.
+func closeOnShutdown(m *kms.Manager, l stdnet.Listener) {
+	go func() {
+		<-m.DrainStarted()
+
+		// the listener may already have been closed by it's user eg. kmshttp
+		if err := l.Close(); err != nil && !errors.Is(err, stdnet.ErrClosed) {
+			m.Logger().Error("kmsnet: closing listener", "addr", l.Addr().String(), "error", err)
+		}
+	}()
+}
+
+// Wrap returns the listener pre-wired with the provided kms.Manager, but no shutdown
+// signals allowing for a custom shutdown to be implemented by the caller. Listeners
+// already wired by kmsnet are returned as is.
+//
+// currently only net.TCPListener and net.UnixListener is supported
+func Wrap(m *kms.Manager, l stdnet.Listener) (stdnet.Listener, error) {
+
+	switch t := l.(type) {
+	case *tcpListener, *unixListener:
+		return l, nil
+	case *stdnet.TCPListener:
+		return NewTCPNoShutdownWithManager(m, t), nil
+	case *stdnet.UnixListener:
+		return NewUnixNoShutdownWithManager(m, t), nil
+	default:
+		return nil, fmt.Errorf("kmsnet: unsupported listener type %T", l)
+	}
+}
+
+// connTracker tracks the active connections of a listener; their number is reported by kms.Stats()
+// as "kmsnet.conns:<network>:<addr>" until the listener is closed and it's connections drained, and
+// they are closed at the kms.StepForceClose step of the escalation ladder until then.
+type connTracker struct {
+	mu                   sync.Mutex
+	conns                map[stdnet.Conn]struct{}
+	closed               bool
+	released             bool
+	unregisterGauge      func()
+	unregisterForceClose func()
+}
+
+func newConnTracker(m *kms.Manager, addr stdnet.Addr) *connTracker {
+
+	name := "kmsnet.conns:" + addr.Network() + ":" + addr.String()
+
+	t := &connTracker{conns: make(map[stdnet.Conn]struct{})}
+	t.unregisterGauge = m.RegisterGauge(name, t.active)
+	t.unregisterForceClose = m.OnForceClose(name, t.forceClose)
+
+	return t
+}
+
+func (t *connTracker) active() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return int64(len(t.conns))
+}
+
+func (t *connTracker) add(c stdnet.Conn) {
+	t.mu.Lock()
+	t.conns[c] = struct{}{}
+	t.mu.Unlock()
+}
+
+func (t *connTracker) remove(c stdnet.Conn) {
+	t.mu.Lock()
+	delete(t.conns, c)
+	t.release()
+	t.mu.Unlock()
+}
+
+func (t *connTracker) close() {
+	t.mu.Lock()
+	t.closed = true
+	t.release()
+	t.mu.Unlock()
+}
+
+// release unregisters the gauge and force close hook once the listener is closed and drained,
+// must be called with mu held.
+func (t *connTracker) release() {
+	if t.closed && len(t.conns) == 0 && !t.released {
+		t.released = true
+		t.unregisterGauge()
+		t.unregisterForceClose()
+	}
+}
+
+func (t *connTracker) forceClose(ctx context.Context) error {
+
+	t.mu.Lock()
+
+	conns := make([]stdnet.Conn, 0, len(t.conns))
+	for c := range t.conns {
+		conns = append(conns, c)
+	}
+
+	t.mu.Unlock()
+
+	var errs []error
+
+	for _, c := range conns {
+		if err := c.Close(); err != nil && !errors.Is(err, stdnet.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
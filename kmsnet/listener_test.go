@@ -0,0 +1,100 @@
+package kmsnet
+
+import (
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-playground/kms"
+)
+
+func TestListenerConnGauge(t *testing.T) {
+
+	m := kms.New(kms.WithSignalFn(func() <-chan os.Signal { return make(chan os.Signal) }))
+
+	l, err := NewTCPListenerNoShutdownWithManager(m, "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := "kmsnet.conns:tcp:" + l.Addr().String()
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, ok := m.Stats().Gauges[name]; !ok || n != 1 {
+		t.Errorf("Expected '%d' active connections Got '%d'", 1, n)
+	}
+
+	l.Close()
+
+	// the gauge is reported until the listeners connections have drained
+	if _, ok := m.Stats().Gauges[name]; !ok {
+		t.Errorf("Expected gauge '%s' while connections are active", name)
+	}
+
+	conn.Close()
+
+	if _, ok := m.Stats().Gauges[name]; ok {
+		t.Errorf("Expected gauge '%s' to be unregistered", name)
+	}
+}
+
+func TestListenerForceClose(t *testing.T) {
+
+	exited := make(chan int, 1)
+
+	m := kms.New(
+		kms.WithSignalFn(func() <-chan os.Signal { return make(chan os.Signal) }),
+		kms.WithExitFunc(func(code int) { exited <- code }),
+		kms.WithEscalation(kms.Escalation{LastGaspAfter: time.Second}),
+	)
+
+	l, err := NewTCPListenerWithManager(m, "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if _, err = l.Accept(); err != nil {
+		t.Fatal(err)
+	}
+
+	m.ListenTimeout(false, time.Millisecond*50)
+	m.Shutdown(nil)
+
+	client.SetReadDeadline(time.Now().Add(time.Second))
+
+	// the server side of the connection is closed at the force close step
+	if _, err = client.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("Expected '%v' Got '%v'", io.EOF, err)
+	}
+
+	// closing the connection completes the operation, allowing the shutdown to complete
+	select {
+	case <-m.ShutdownComplete():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected shutdown to complete once connections are force closed")
+	}
+
+	select {
+	case <-exited:
+		t.Errorf("Expected no exit as the shutdown completed")
+	default:
+	}
+}
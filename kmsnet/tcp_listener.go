@@ -1,7 +1,7 @@
 package kmsnet
 
 import (
-	"log"
+	"fmt"
 	stdnet "net"
 	"os"
 	"time"
@@ -12,54 +12,105 @@ import (
 // NewTCPListener returns an instance of a net.Listener that
 // is pre-wired with notification and shutdown siganls.
 func NewTCPListener(net, laddr string) (stdnet.Listener, error) {
+	return NewTCPListenerWithManager(kms.Default(), net, laddr)
+}
 
-	tcpAddr, err := stdnet.ResolveTCPAddr(net, laddr)
-	if err != nil {
-		return nil, err
-	}
+// NewTCPListenerWithManager returns an instance of a net.Listener that
+// is pre-wired with notification and shutdown siganls of the provided kms.Manager.
+func NewTCPListenerWithManager(m *kms.Manager, net, laddr string) (stdnet.Listener, error) {
 
-	l, err := stdnet.ListenTCP(net, tcpAddr)
+	l, err := listenTCP(m, net, laddr)
 	if err != nil {
 		return nil, err
 	}
 
-	go func() {
-		<-kms.ShutdownInitiated()
-		if err := l.Close(); err != nil {
-			log.Println(err)
-		}
-	}()
+	tl := wrapTCP(m, l)
+	closeOnShutdown(m, tl)
 
-	return &tcpListener{l}, nil
+	return tl, nil
 }
 
 // NewTCPListenerNoShutdown returns an instance of a net.Listener that
 // is pre-wired with kms, but no shutdown signals allowing for a custom
 // shutdown to be implemented by the caller.
 func NewTCPListenerNoShutdown(net, laddr string) (stdnet.Listener, error) {
+	return NewTCPListenerNoShutdownWithManager(kms.Default(), net, laddr)
+}
 
-	tcpAddr, err := stdnet.ResolveTCPAddr(net, laddr)
-	if err != nil {
-		return nil, err
-	}
+// NewTCPListenerNoShutdownWithManager returns an instance of a net.Listener that
+// is pre-wired with the provided kms.Manager, but no shutdown signals allowing for
+// a custom shutdown to be implemented by the caller.
+func NewTCPListenerNoShutdownWithManager(m *kms.Manager, net, laddr string) (stdnet.Listener, error) {
 
-	l, err := stdnet.ListenTCP(net, tcpAddr)
+	l, err := listenTCP(m, net, laddr)
 	if err != nil {
 		return nil, err
 	}
 
-	return &tcpListener{l}, nil
+	return wrapTCP(m, l), nil
 }
 
 // NewTCPNoShutdown returns an instance of a net.Listener that
 // is pre-wired with kms, but no shutdown signals allowing for a custom
 // shutdown to be implemented by the caller.
 func NewTCPNoShutdown(l *stdnet.TCPListener) stdnet.Listener {
-	return &tcpListener{l}
+	return NewTCPNoShutdownWithManager(kms.Default(), l)
+}
+
+// NewTCPNoShutdownWithManager returns an instance of a net.Listener that
+// is pre-wired with the provided kms.Manager, but no shutdown signals allowing for
+// a custom shutdown to be implemented by the caller.
+func NewTCPNoShutdownWithManager(m *kms.Manager, l *stdnet.TCPListener) stdnet.Listener {
+	register(m, l.Addr().Network(), l.Addr().String(), l)
+	return wrapTCP(m, l)
+}
+
+// listenTCP returns the listener inherited from the parent process, see Upgrade(), or
+// creates a new one; either way it is registered to be handed off during an upgrade of the
+// kms.Manager.
+func listenTCP(m *kms.Manager, network, laddr string) (*stdnet.TCPListener, error) {
+
+	il, err := inheritedListener(network, laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if il != nil {
+
+		l, ok := il.(*stdnet.TCPListener)
+		if !ok {
+			il.Close()
+			return nil, fmt.Errorf("kmsnet: inherited listener %s:%s is not a TCP listener", network, laddr)
+		}
+
+		register(m, network, laddr, l)
+
+		return l, nil
+	}
+
+	tcpAddr, err := stdnet.ResolveTCPAddr(network, laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := stdnet.ListenTCP(network, tcpAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	register(m, network, laddr, l)
+
+	return l, nil
 }
 
 type tcpListener struct {
 	*stdnet.TCPListener
+	m     *kms.Manager
+	conns *connTracker
+}
+
+func wrapTCP(m *kms.Manager, l *stdnet.TCPListener) *tcpListener {
+	return &tcpListener{TCPListener: l, m: m, conns: newConnTracker(m, l.Addr())}
 }
 
 var _ stdnet.Listener = new(tcpListener)
@@ -75,16 +126,21 @@ func (l *tcpListener) Accept() (stdnet.Conn, error) {
 	conn.SetKeepAlivePeriod(time.Minute * 3) // see http.tcpKeepAliveListener
 	// conn.SetLinger(0) // is the default already according to the docs https://golang.org/pkg/net/#TCPConn.SetLinger
 
-	kms.Wait()
+	op := l.m.Track("kmsnet.conn", "network", "tcp", "local", conn.LocalAddr().String(), "remote", conn.RemoteAddr().String())
+
+	c := &zeroTCPConn{TCPConn: conn, op: op, conns: l.conns}
+	l.conns.add(c)
 
-	return &zeroTCPConn{TCPConn: conn}, nil
+	return c, nil
 }
 
 // blocking wait for close
 func (l *tcpListener) Close() (err error) {
 
 	//stop accepting connections - release fd
+	unregister(l.TCPListener)
 	err = l.TCPListener.Close()
+	l.conns.close()
 	return
 }
 
@@ -97,14 +153,17 @@ func (l *tcpListener) File() *os.File {
 	return fl
 }
 
-//notifying on close net.Conn
+// notifying on close net.Conn
 type zeroTCPConn struct {
 	*stdnet.TCPConn
+	op    *kms.Operation
+	conns *connTracker
 }
 
-func (conn zeroTCPConn) Close() (err error) {
+func (conn *zeroTCPConn) Close() (err error) {
 	if err = conn.TCPConn.Close(); err == nil {
-		kms.Done()
+		conn.op.Done()
+		conn.conns.remove(conn)
 	}
 	return
 }
@@ -0,0 +1,63 @@
+package kmsnet
+
+import (
+	"context"
+	"crypto/tls"
+	"sync/atomic"
+
+	"github.com/go-playground/kms"
+)
+
+// CertificateReloader holds a TLS certificate that is re-read from disk whenever
+// the kms.Manager it is registered with reloads, see kms.OnReload()
+type CertificateReloader struct {
+	certFile   string
+	keyFile    string
+	cert       atomic.Value // *tls.Certificate
+	unregister func()
+}
+
+// NewCertificateReloader loads the certificate and matching private key from the provided files
+// and registers a reload handler with the kms.Manager to re-read them until Close is called.
+//
+// use the returned CertificateReloader's GetCertificate as the tls.Config GetCertificate function.
+func NewCertificateReloader(m *kms.Manager, certFile, keyFile string) (*CertificateReloader, error) {
+
+	c := &CertificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if err := c.Reload(context.Background()); err != nil {
+		return nil, err
+	}
+
+	c.unregister = m.OnReload("kmsnet: tls certificate "+certFile, c.Reload)
+
+	return c, nil
+}
+
+// Close unregisters the reload handler, the current certificate continues to be served.
+func (c *CertificateReloader) Close() {
+	c.unregister()
+}
+
+// Reload re-reads the certificate and private key, the current certificate is kept
+// when an error occurs.
+func (c *CertificateReloader) Reload(ctx context.Context) error {
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+
+	c.cert.Store(&cert)
+
+	return nil
+}
+
+// GetCertificate returns the current certificate, it's signature matches tls.Config's
+// GetCertificate
+func (c *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.cert.Load().(*tls.Certificate), nil
+}
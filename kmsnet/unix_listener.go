@@ -1,7 +1,7 @@
 package kmsnet
 
 import (
-	"log"
+	"fmt"
 	stdnet "net"
 	"os"
 
@@ -11,54 +11,108 @@ import (
 // NewUnixListener returns an instance of a net.Listener that
 // is pre-wired with notification and shutdown siganls.
 func NewUnixListener(net, laddr string) (stdnet.Listener, error) {
+	return NewUnixListenerWithManager(kms.Default(), net, laddr)
+}
 
-	unixAddr, err := stdnet.ResolveUnixAddr(net, laddr)
-	if err != nil {
-		return nil, err
-	}
+// NewUnixListenerWithManager returns an instance of a net.Listener that
+// is pre-wired with notification and shutdown siganls of the provided kms.Manager.
+func NewUnixListenerWithManager(m *kms.Manager, net, laddr string) (stdnet.Listener, error) {
 
-	l, err := stdnet.ListenUnix(net, unixAddr)
+	l, err := listenUnix(m, net, laddr)
 	if err != nil {
 		return nil, err
 	}
 
-	go func() {
-		<-kms.ShutdownInitiated()
-		if err := l.Close(); err != nil {
-			log.Println(err)
-		}
-	}()
+	tl := wrapUnix(m, l)
+	closeOnShutdown(m, tl)
 
-	return &unixListener{l}, nil
+	return tl, nil
 }
 
 // NewUnixListenerNoShutdown returns an instance of a net.Listener that
 // is pre-wired with kms, but no shutdown signals allowing for a custom
 // shutdown to be implemented by the caller.
 func NewUnixListenerNoShutdown(net, laddr string) (stdnet.Listener, error) {
+	return NewUnixListenerNoShutdownWithManager(kms.Default(), net, laddr)
+}
 
-	unixAddr, err := stdnet.ResolveUnixAddr(net, laddr)
-	if err != nil {
-		return nil, err
-	}
+// NewUnixListenerNoShutdownWithManager returns an instance of a net.Listener that
+// is pre-wired with the provided kms.Manager, but no shutdown signals allowing for
+// a custom shutdown to be implemented by the caller.
+func NewUnixListenerNoShutdownWithManager(m *kms.Manager, net, laddr string) (stdnet.Listener, error) {
 
-	l, err := stdnet.ListenUnix(net, unixAddr)
+	l, err := listenUnix(m, net, laddr)
 	if err != nil {
 		return nil, err
 	}
 
-	return &unixListener{l}, nil
+	return wrapUnix(m, l), nil
 }
 
 // NewUnixNoShutdown returns an instance of a net.Listener that
 // is pre-wired with kms, but no shutdown signals allowing for a custom
 // shutdown to be implemented by the caller.
 func NewUnixNoShutdown(l *stdnet.UnixListener) stdnet.Listener {
-	return &unixListener{l}
+	return NewUnixNoShutdownWithManager(kms.Default(), l)
+}
+
+// NewUnixNoShutdownWithManager returns an instance of a net.Listener that
+// is pre-wired with the provided kms.Manager, but no shutdown signals allowing for
+// a custom shutdown to be implemented by the caller.
+func NewUnixNoShutdownWithManager(m *kms.Manager, l *stdnet.UnixListener) stdnet.Listener {
+	register(m, l.Addr().Network(), l.Addr().String(), l)
+	return wrapUnix(m, l)
+}
+
+// listenUnix returns the listener inherited from the parent process, see Upgrade(), or
+// creates a new one; either way it is registered to be handed off during an upgrade of the
+// kms.Manager.
+func listenUnix(m *kms.Manager, network, laddr string) (*stdnet.UnixListener, error) {
+
+	il, err := inheritedListener(network, laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if il != nil {
+
+		l, ok := il.(*stdnet.UnixListener)
+		if !ok {
+			il.Close()
+			return nil, fmt.Errorf("kmsnet: inherited listener %s:%s is not a Unix listener", network, laddr)
+		}
+
+		// the socket file is this processes to remove now, the parent stopped unlinking it
+		l.SetUnlinkOnClose(true)
+
+		register(m, network, laddr, l)
+
+		return l, nil
+	}
+
+	unixAddr, err := stdnet.ResolveUnixAddr(network, laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := stdnet.ListenUnix(network, unixAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	register(m, network, laddr, l)
+
+	return l, nil
 }
 
 type unixListener struct {
 	*stdnet.UnixListener
+	m     *kms.Manager
+	conns *connTracker
+}
+
+func wrapUnix(m *kms.Manager, l *stdnet.UnixListener) *unixListener {
+	return &unixListener{UnixListener: l, m: m, conns: newConnTracker(m, l.Addr())}
 }
 
 var _ stdnet.Listener = new(unixListener)
@@ -70,16 +124,21 @@ func (l *unixListener) Accept() (stdnet.Conn, error) {
 		return nil, err
 	}
 
-	kms.Wait()
+	op := l.m.Track("kmsnet.conn", "network", "unix", "local", conn.LocalAddr().String())
+
+	c := zeroUinxConn{Conn: conn, op: op, conns: l.conns}
+	l.conns.add(c)
 
-	return zeroUinxConn{Conn: conn}, nil
+	return c, nil
 }
 
 // blocking wait for close
 func (l *unixListener) Close() (err error) {
 
 	//stop accepting connections - release fd
+	unregister(l.UnixListener)
 	err = l.UnixListener.Close()
+	l.conns.close()
 	return
 }
 
@@ -92,15 +151,18 @@ func (l *unixListener) File() *os.File {
 	return fl
 }
 
-//notifying on close net.Conn
+// notifying on close net.Conn
 type zeroUinxConn struct {
 	stdnet.Conn
+	op    *kms.Operation
+	conns *connTracker
 }
 
 func (conn zeroUinxConn) Close() (err error) {
 
 	if err = conn.Conn.Close(); err == nil {
-		kms.Done()
+		conn.op.Done()
+		conn.conns.remove(conn)
 	}
 	return
 }
@@ -0,0 +1,318 @@
+package kmsnet
+
+import (
+	"errors"
+	"fmt"
+	stdnet "net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/kms"
+)
+
+const (
+	// envListenerFDs names the listeners inherited from the parent process
+	// as a comma separated list of fd:network:address
+	envListenerFDs = "KMS_LISTENER_FDS"
+
+	// envReadyFD is the fd of the pipe the child process signals readiness on
+	envReadyFD = "KMS_UPGRADE_READY_FD"
+)
+
+// ErrUpgraded is the shutdown reason recorded once the upgraded process has signalled
+// it is ready; it wraps kms.ErrShutdownRequested so is treated as a clean shutdown by the
+// kms.ExitPolicy.
+var ErrUpgraded = fmt.Errorf("%w: upgraded", kms.ErrShutdownRequested)
+
+// ErrUpgradeInProgress is returned by Upgrade when an upgrade is already in progress.
+var ErrUpgradeInProgress = errors.New("kmsnet: upgrade in progress")
+
+type listenerKey struct {
+	network string
+	addr    string
+}
+
+func (k listenerKey) String() string {
+	return k.network + ":" + k.addr
+}
+
+type filer interface {
+	File() (*os.File, error)
+}
+
+// registered is a listener created by kmsnet and the kms.Manager it belongs to.
+type registered struct {
+	l filer
+	m *kms.Manager
+}
+
+var (
+	// listeners created by kmsnet which are handed off during an upgrade of their kms.Manager
+	registry = struct {
+		sync.Mutex
+		listeners map[listenerKey]registered
+	}{
+		listeners: make(map[listenerKey]registered),
+	}
+
+	upgrading sync.Mutex
+
+	// listeners and ready pipe inherited from the parent process
+	inherited = struct {
+		sync.Mutex
+		once  sync.Once
+		files map[listenerKey]*os.File
+		ready *os.File
+	}{}
+)
+
+func register(m *kms.Manager, network, addr string, l filer) {
+	registry.Lock()
+	registry.listeners[listenerKey{network: network, addr: addr}] = registered{l: l, m: m}
+	registry.Unlock()
+}
+
+// unregister removes the listener once closed, so it is no longer handed off.
+func unregister(l filer) {
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	for key, r := range registry.listeners {
+		if r.l == l {
+			delete(registry.listeners, key)
+		}
+	}
+}
+
+// loadInherited parses the environment once, the variables are removed so
+// they are not passed on to any further child processes.
+func loadInherited() {
+
+	inherited.once.Do(func() {
+
+		inherited.files = make(map[listenerKey]*os.File)
+
+		if v := os.Getenv(envListenerFDs); v != "" {
+
+			for _, entry := range strings.Split(v, ",") {
+
+				parts := strings.SplitN(entry, ":", 3)
+				if len(parts) != 3 {
+					continue
+				}
+
+				fd, err := strconv.Atoi(parts[0])
+				if err != nil {
+					continue
+				}
+
+				key := listenerKey{network: parts[1], addr: parts[2]}
+				inherited.files[key] = os.NewFile(uintptr(fd), key.String())
+			}
+		}
+
+		if v := os.Getenv(envReadyFD); v != "" {
+			if fd, err := strconv.Atoi(v); err == nil {
+				inherited.ready = os.NewFile(uintptr(fd), "kms-upgrade-ready")
+			}
+		}
+
+		os.Unsetenv(envListenerFDs)
+		os.Unsetenv(envReadyFD)
+	})
+}
+
+// inheritedListener returns the listener inherited from the parent process for the
+// network and address, nil if none was inherited.
+func inheritedListener(network, addr string) (stdnet.Listener, error) {
+
+	loadInherited()
+
+	key := listenerKey{network: network, addr: addr}
+
+	inherited.Lock()
+	f, ok := inherited.files[key]
+	delete(inherited.files, key)
+	inherited.Unlock()
+
+	if !ok {
+		return nil, nil
+	}
+
+	// net.FileListener dup's the fd
+	defer f.Close()
+
+	return stdnet.FileListener(f)
+}
+
+// Ready signals the parent process, when started by Upgrade, that this process has created
+// all of it's listeners and is ready to accept connections; the parent then begins it's
+// graceful shutdown. Any inherited listeners not re-created are closed.
+//
+// It is safe to call Ready when the process was not started by Upgrade. When serving using
+// kmshttp, whose functions block once the listener is created, use kmshttp.UpgradeReady().
+func Ready() error {
+
+	loadInherited()
+
+	inherited.Lock()
+	defer inherited.Unlock()
+
+	for key, f := range inherited.files {
+		f.Close()
+		delete(inherited.files, key)
+	}
+
+	if inherited.ready == nil {
+		return nil
+	}
+
+	_, err := inherited.ready.Write([]byte{1})
+	inherited.ready.Close()
+	inherited.ready = nil
+
+	return err
+}
+
+// Upgrade performs a zero downtime binary upgrade by re-executing the current binary with the
+// kmsnet listeners of the kms.Manager inherited, listeners of other Managers and those already
+// closed are not handed off. The child process re-creates the same listeners, by calling
+// the kmsnet listener functions with the same network and address, and must call Ready() once
+// ready to accept connections; only then does this process start it's graceful shutdown.
+//
+// An error is returned if the child process fails to start, exits or is not ready within timeout.
+func Upgrade(m *kms.Manager, timeout time.Duration) error {
+
+	if !upgrading.TryLock() {
+		return ErrUpgradeInProgress
+	}
+	defer upgrading.Unlock()
+
+	select {
+	case <-m.ShutdownInitiated():
+		return kms.ErrShuttingDown
+	default:
+	}
+
+	path, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	registry.Lock()
+
+	var files []*os.File
+	var names []string
+	var unix []*stdnet.UnixListener
+
+	for key, r := range registry.listeners {
+
+		// only the listeners of the Manager being upgraded, any other's keep serving
+		if r.m != m {
+			continue
+		}
+
+		f, err := r.l.File()
+		if err != nil {
+			// the listener has been closed
+			continue
+		}
+
+		if ul, ok := r.l.(*stdnet.UnixListener); ok {
+			unix = append(unix, ul)
+		}
+
+		names = append(names, fmt.Sprintf("%d:%s", 3+len(files), key))
+		files = append(files, f)
+	}
+
+	registry.Unlock()
+
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = append(files, w)
+	cmd.Env = append(os.Environ(),
+		envListenerFDs+"="+strings.Join(names, ","),
+		envReadyFD+"="+strconv.Itoa(3+len(files)),
+	)
+
+	err = cmd.Start()
+	w.Close()
+
+	if err != nil {
+		return err
+	}
+
+	log := m.Logger()
+	log.Info("kmsnet: upgrade started", "pid", cmd.Process.Pid, "listeners", len(files))
+
+	ready := make(chan error, 1)
+
+	go func() {
+		// a read returning without data means the child exited before signalling readiness
+		b := make([]byte, 1)
+		if n, _ := r.Read(b); n == 0 {
+			ready <- errors.New("kmsnet: upgraded process exited before becoming ready")
+			return
+		}
+		ready <- nil
+	}()
+
+	select {
+	case err = <-ready:
+	case <-m.Clock().After(timeout):
+		err = errors.New("kmsnet: upgraded process not ready within timeout")
+	}
+
+	if err != nil {
+		log.Error("kmsnet: upgrade failed", "pid", cmd.Process.Pid, "error", err)
+		cmd.Process.Kill()
+		go cmd.Wait()
+		return err
+	}
+
+	// the child is adopted by init once this process exits
+	cmd.Process.Release()
+
+	// the socket files must outlive this processes listeners, only once the child has taken
+	// them over so that they are still removed when the upgrade fails.
+	for _, ul := range unix {
+		ul.SetUnlinkOnClose(false)
+	}
+
+	log.Info("kmsnet: upgrade complete", "pid", cmd.Process.Pid)
+	m.Shutdown(ErrUpgraded)
+
+	return nil
+}
+
+// UpgradeHandler returns a function, suitable for kms.SignalRouter.HandleFunc, which
+// performs an Upgrade whenever the signal is received.
+//
+// eg. r.HandleFunc(kmsnet.UpgradeHandler(m, time.Minute), syscall.SIGUSR2)
+func UpgradeHandler(m *kms.Manager, timeout time.Duration) func(os.Signal) {
+	return func(sig os.Signal) {
+		if err := Upgrade(m, timeout); err != nil {
+			m.Logger().Error("kmsnet: upgrade on signal failed", "signal", sig.String(), "error", err)
+		}
+	}
+}
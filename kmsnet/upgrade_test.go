@@ -0,0 +1,274 @@
+package kmsnet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	stdnet "net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-playground/kms"
+)
+
+const (
+	// envUpgradeChild is set when the test binary is re-executed by Upgrade, naming how the
+	// child process behaves.
+	envUpgradeChild = "KMSNET_TEST_UPGRADE_CHILD"
+
+	// upgradeAddr is the address the listener is created with, listeners are inherited by
+	// the address they were created with rather than the one they are bound to.
+	upgradeAddr = "127.0.0.1:0"
+)
+
+func TestMain(m *testing.M) {
+
+	if mode := os.Getenv(envUpgradeChild); mode != "" {
+		upgradeChild(mode)
+		return
+	}
+
+	os.Exit(m.Run())
+}
+
+// upgradeChild acts as the process started by Upgrade.
+func upgradeChild(mode string) {
+
+	// never outlive the test
+	time.AfterFunc(time.Second*10, func() { os.Exit(3) })
+
+	// only the listener of the Manager being upgraded is inherited
+	if fds := os.Getenv(envListenerFDs); fds == "" || strings.Contains(fds, ",") {
+		os.Exit(2)
+	}
+
+	switch mode {
+	case "exit":
+		os.Exit(1)
+	case "hang":
+		select {}
+	}
+
+	l, err := NewTCPListenerNoShutdownWithManager(kms.New(), "tcp", upgradeAddr)
+	if err != nil {
+		os.Exit(2)
+	}
+
+	if err = Ready(); err != nil {
+		os.Exit(2)
+	}
+
+	conn, err := l.Accept()
+	if err != nil {
+		os.Exit(2)
+	}
+
+	conn.Write([]byte("child"))
+	conn.Close()
+
+	os.Exit(0)
+}
+
+// resetInherited allows the environment to be re-parsed by the next listener
+func resetInherited() {
+	inherited.Lock()
+	inherited.once = sync.Once{}
+	inherited.files = nil
+	inherited.ready = nil
+	inherited.Unlock()
+}
+
+func TestInheritedListener(t *testing.T) {
+
+	parent, err := stdnet.ListenTCP("tcp", &stdnet.TCPAddr{IP: stdnet.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parent.Close()
+
+	f, err := parent.File()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := parent.Addr().String()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	os.Setenv(envListenerFDs, fmt.Sprintf("%d:tcp:%s", f.Fd(), addr))
+	os.Setenv(envReadyFD, fmt.Sprintf("%d", w.Fd()))
+
+	resetInherited()
+	defer resetInherited()
+
+	m := kms.New()
+
+	l, err := NewTCPListenerNoShutdownWithManager(m, "tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if l.Addr().String() != addr {
+		t.Errorf("Expected '%s' Got '%s'", addr, l.Addr().String())
+	}
+
+	if os.Getenv(envListenerFDs) != "" || os.Getenv(envReadyFD) != "" {
+		t.Errorf("Expected inherited environment to be cleared")
+	}
+
+	go func() {
+		conn, err := stdnet.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	if err = Ready(); err != nil {
+		t.Fatal(err)
+	}
+
+	b := make([]byte, 1)
+	if n, _ := r.Read(b); n != 1 {
+		t.Errorf("Expected readiness to be signalled")
+	}
+
+	// Ready is safe to call more than once
+	if err = Ready(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+
+	m := kms.New(kms.WithSignalFn(func() <-chan os.Signal { return make(chan os.Signal) }))
+
+	l, err := NewTCPListenerNoShutdownWithManager(m, "tcp", upgradeAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	addr := l.Addr().String()
+
+	// another Manager's listeners are not handed off
+	other, err := NewUnixListenerNoShutdownWithManager(kms.New(), "unix", filepath.Join(t.TempDir(), "other.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	t.Setenv(envUpgradeChild, "ready")
+
+	if err = Upgrade(m, time.Second*10); err != nil {
+		t.Fatal(err)
+	}
+
+	if !errors.Is(m.ShutdownReason(), ErrUpgraded) {
+		t.Errorf("Expected '%v' Got '%v'", ErrUpgraded, m.ShutdownReason())
+	}
+
+	// only the child, holding the inherited listener, can accept once this one is closed
+	l.Close()
+
+	conn, err := stdnet.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(time.Second * 10))
+
+	b, _ := io.ReadAll(conn)
+
+	if string(b) != "child" {
+		t.Errorf("Expected '%s' Got '%s'", "child", b)
+	}
+}
+
+func TestUpgradeFailed(t *testing.T) {
+
+	tests := []struct {
+		mode string
+		err  string
+	}{
+		{"exit", "exited before becoming ready"},
+		{"hang", "not ready within timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+
+			m := kms.New(kms.WithSignalFn(func() <-chan os.Signal { return make(chan os.Signal) }))
+
+			path := filepath.Join(t.TempDir(), "kms.sock")
+
+			l, err := NewUnixListenerNoShutdownWithManager(m, "unix", path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Setenv(envUpgradeChild, tt.mode)
+
+			err = Upgrade(m, time.Millisecond*500)
+
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected '%s' Got '%v'", tt.err, err)
+			}
+
+			if m.ShutdownReason() != nil {
+				t.Errorf("Expected no shutdown Got '%v'", m.ShutdownReason())
+			}
+
+			// the socket file is still removed as the upgrade failed
+			l.Close()
+
+			if _, err = os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("Expected socket file to be removed Got '%v'", err)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+
+	m := kms.New()
+	key := listenerKey{network: "unix", addr: filepath.Join(t.TempDir(), "kms.sock")}
+
+	l, err := NewUnixListenerNoShutdownWithManager(m, key.network, key.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry.Lock()
+	r, ok := registry.listeners[key]
+	registry.Unlock()
+
+	if !ok || r.m != m {
+		t.Fatalf("Expected the listener to be registered to it's Manager")
+	}
+
+	l.Close()
+
+	registry.Lock()
+	_, ok = registry.listeners[key]
+	registry.Unlock()
+
+	if ok {
+		t.Errorf("Expected the listener to be unregistered once closed")
+	}
+}
@@ -0,0 +1,382 @@
+// Package kmspool provides a worker pool, with bounded concurrency and a bounded queue, which
+// respects the kms lifecycle; once a shutdown is initiated no new items are accepted and the
+// queued items are drained, handed off or dropped according to the pool's Policy.
+//
+//	p := kmspool.New("emails", 8, func(ctx context.Context, e Email) error {
+//		return send(ctx, e)
+//	}, kmspool.WithHandOff(func(ctx context.Context, queued []Email) error {
+//		return db.SaveUnsent(ctx, queued)
+//	}))
+//
+//	if err := p.Submit(ctx, email); err != nil {
+//		...
+//	}
+package kmspool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-playground/kms"
+)
+
+var (
+	// ErrClosed is returned when submitting to a pool once a shutdown has been initiated.
+	ErrClosed = errors.New("kmspool: pool closed")
+
+	// ErrQueueFull is returned by TrySubmit when the queue is full.
+	ErrQueueFull = errors.New("kmspool: queue full")
+)
+
+// Policy determines what happens to queued items once a shutdown is initiated, items already
+// being processed are always allowed to finish.
+type Policy uint8
+
+// Shutdown policies
+const (
+	// PolicyDrain processes all queued items before the pool completes.
+	PolicyDrain Policy = iota
+
+	// PolicyHandOff passes the queued items to the hand-off callback, eg. for persistence,
+	// see WithHandOff()
+	PolicyHandOff
+
+	// PolicyDrop discards the queued items.
+	PolicyDrop
+)
+
+// String returns the name of the Policy
+func (p Policy) String() string {
+	switch p {
+	case PolicyDrain:
+		return "drain"
+	case PolicyHandOff:
+		return "hand-off"
+	case PolicyDrop:
+		return "drop"
+	default:
+		return fmt.Sprintf("Policy(%d)", uint8(p))
+	}
+}
+
+type options struct {
+	queueSize int
+	policy    Policy
+	handOff   any
+}
+
+// Option configures a Pool, see New()
+type Option func(*options)
+
+// WithQueueSize sets the maximum number of items waiting for a worker.
+//
+// Default: the number of workers
+func WithQueueSize(n int) Option {
+	return func(o *options) {
+		o.queueSize = n
+	}
+}
+
+// WithPolicy sets the pool's shutdown Policy, PolicyHandOff must be set using WithHandOff().
+//
+// Default: PolicyDrain
+func WithPolicy(p Policy) Option {
+	return func(o *options) {
+		o.policy = p
+	}
+}
+
+// WithHandOff sets the pool's shutdown Policy to PolicyHandOff; once the in-flight items have
+// been processed fn is called, with kms.HardStopContext(), with the items still queued. The item
+// type must match the Pool's.
+func WithHandOff[T any](fn func(ctx context.Context, queued []T) error) Option {
+	return func(o *options) {
+		o.policy = PolicyHandOff
+		o.handOff = fn
+	}
+}
+
+type queued[T any] struct {
+	item T
+	op   *kms.Operation
+}
+
+// Pool processes submitted items using a fixed number of workers until a shutdown of it's
+// kms.Manager is initiated, including one after the Manager has been Reset. Once the pool has
+// closed it's done for good, a new Pool must be created for the next lifecycle.
+//
+// Each item is tracked as a kms operation, named "kmspool.item" labelled with the pool name, from
+// it's submission until it has been processed, handed off or dropped so the shutdown waits for
+// it. The number of queued and in-flight items are reported by kms.Stats() as
+// "kmspool.queued:<name>" and "kmspool.inflight:<name>" until the pool has completed.
+type Pool[T any] struct {
+	m       *kms.Manager
+	name    string
+	fn      func(ctx context.Context, item T) error
+	policy  Policy
+	handOff func(ctx context.Context, queued []T) error
+
+	queue    chan queued[T]
+	inFlight atomic.Int64
+
+	// shutdown is closed once a shutdown of the kms.Manager is initiated, see kms.NotifyContext()
+	shutdown <-chan struct{}
+
+	mu      sync.Mutex
+	closed  bool
+	closing chan struct{}
+	senders sync.WaitGroup
+	workers sync.WaitGroup
+
+	// items received by workers after the shutdown was initiated, when not draining.
+	leftover []queued[T]
+
+	done chan struct{}
+}
+
+// New returns a new Pool, tied to the lifecycle of the package level kms functions, processing
+// items using fn with the provided number of workers.
+func New[T any](name string, workers int, fn func(ctx context.Context, item T) error, opts ...Option) *Pool[T] {
+	return NewWithManager(kms.Default(), name, workers, fn, opts...)
+}
+
+// NewWithManager returns a new Pool, tied to the lifecycle of the provided kms.Manager, processing
+// items using fn with the provided number of workers.
+func NewWithManager[T any](m *kms.Manager, name string, workers int, fn func(ctx context.Context, item T) error, opts ...Option) *Pool[T] {
+
+	if workers <= 0 {
+		panic("kmspool: workers must be positive")
+	}
+
+	o := options{queueSize: workers}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.queueSize < 0 {
+		panic("kmspool: queue size must not be negative")
+	}
+
+	p := &Pool[T]{
+		m:       m,
+		name:    name,
+		fn:      fn,
+		policy:  o.policy,
+		queue:   make(chan queued[T], o.queueSize),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	if p.policy == PolicyHandOff {
+
+		if o.handOff == nil {
+			panic("kmspool: PolicyHandOff requires a hand-off callback, see WithHandOff()")
+		}
+
+		handOff, ok := o.handOff.(func(ctx context.Context, queued []T) error)
+		if !ok {
+			panic("kmspool: hand-off item type does not match the pool's")
+		}
+
+		p.handOff = handOff
+	}
+
+	ctx, stop := m.NotifyContext(context.Background())
+	p.shutdown = ctx.Done()
+
+	unregisterQueued := m.RegisterGauge("kmspool.queued:"+name, p.Queued)
+	unregisterInFlight := m.RegisterGauge("kmspool.inflight:"+name, p.InFlight)
+
+	p.workers.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+
+	go func() {
+		defer close(p.done)
+		defer unregisterInFlight()
+		defer unregisterQueued()
+		defer stop()
+
+		<-p.shutdown
+		p.close()
+	}()
+
+	return p
+}
+
+// Submit queues the item, blocking until there is room in the queue, returning ErrClosed once a
+// shutdown has been initiated or the context's error if it's done first.
+func (p *Pool[T]) Submit(ctx context.Context, item T) error {
+
+	op, err := p.track()
+	if err != nil {
+		return err
+	}
+	defer p.senders.Done()
+
+	select {
+	case p.queue <- queued[T]{item: item, op: op}:
+		return nil
+	case <-p.closing:
+		op.Done()
+		return ErrClosed
+	case <-ctx.Done():
+		op.Done()
+		return ctx.Err()
+	}
+}
+
+// TrySubmit queues the item without blocking, returning ErrQueueFull when there is no room in the
+// queue or ErrClosed once a shutdown has been initiated.
+func (p *Pool[T]) TrySubmit(item T) error {
+
+	op, err := p.track()
+	if err != nil {
+		return err
+	}
+	defer p.senders.Done()
+
+	select {
+	case p.queue <- queued[T]{item: item, op: op}:
+		return nil
+	default:
+		op.Done()
+		return ErrQueueFull
+	}
+}
+
+// track registers a sender and tracks the item, callers must call senders.Done() when the
+// returned error is nil.
+func (p *Pool[T]) track() (*kms.Operation, error) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// the shutdown may have been initiated before the pool noticed
+	select {
+	case <-p.shutdown:
+		return nil, ErrClosed
+	default:
+	}
+
+	if p.closed {
+		return nil, ErrClosed
+	}
+
+	p.senders.Add(1)
+
+	return p.m.Track("kmspool.item", "pool", p.name), nil
+}
+
+// Queued returns the number of items waiting for a worker.
+func (p *Pool[T]) Queued() int64 {
+	return int64(len(p.queue))
+}
+
+// InFlight returns the number of items being processed.
+func (p *Pool[T]) InFlight() int64 {
+	return p.inFlight.Load()
+}
+
+// Done returns a channel which is closed once the pool has applied it's shutdown Policy and all
+// of it's items have been processed, handed off or dropped.
+func (p *Pool[T]) Done() <-chan struct{} {
+	return p.done
+}
+
+func (p *Pool[T]) work() {
+	defer p.workers.Done()
+
+	for q := range p.queue {
+
+		if p.policy != PolicyDrain {
+			select {
+			case <-p.shutdown:
+				p.mu.Lock()
+				p.leftover = append(p.leftover, q)
+				p.mu.Unlock()
+				continue
+			default:
+			}
+		}
+
+		p.process(q)
+	}
+}
+
+func (p *Pool[T]) process(q queued[T]) {
+
+	p.inFlight.Add(1)
+
+	defer func() {
+		p.inFlight.Add(-1)
+		q.op.Done()
+
+		if r := recover(); r != nil {
+			p.m.Logger().Error("kmspool: item panicked", "pool", p.name, "panic", fmt.Sprint(r))
+		}
+	}()
+
+	if err := p.fn(p.m.HardStopContext(), q.item); err != nil {
+		p.m.Logger().Error("kmspool: item failed", "pool", p.name, "error", err)
+	}
+}
+
+// close stops accepting items and applies the pool's Policy to those still queued.
+func (p *Pool[T]) close() {
+
+	p.mu.Lock()
+	p.closed = true
+	close(p.closing)
+	p.mu.Unlock()
+
+	// no sender can be blocked, or start, once closing has been closed.
+	p.senders.Wait()
+	close(p.queue)
+
+	p.workers.Wait()
+
+	if p.policy == PolicyDrain {
+		return
+	}
+
+	// workers stop taking items once the shutdown is initiated but may leave some in the queue.
+	for q := range p.queue {
+		p.leftover = append(p.leftover, q)
+	}
+
+	if len(p.leftover) == 0 {
+		return
+	}
+
+	defer func() {
+		for _, q := range p.leftover {
+			q.op.Done()
+		}
+
+		if r := recover(); r != nil {
+			p.m.Logger().Error("kmspool: hand-off panicked", "pool", p.name, "panic", fmt.Sprint(r))
+		}
+	}()
+
+	if p.policy == PolicyDrop {
+		p.m.Logger().Warn("kmspool: dropping queued items", "pool", p.name, "count", len(p.leftover))
+		return
+	}
+
+	items := make([]T, len(p.leftover))
+
+	for i, q := range p.leftover {
+		items[i] = q.item
+	}
+
+	if err := p.handOff(p.m.HardStopContext(), items); err != nil {
+		p.m.Logger().Error("kmspool: hand-off failed", "pool", p.name, "count", len(items), "error", err)
+	}
+}
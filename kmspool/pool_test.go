@@ -0,0 +1,228 @@
+package kmspool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-playground/kms/kmstest"
+)
+
+func TestPoolPolicies(t *testing.T) {
+
+	tests := []struct {
+		policy    Policy
+		processed []int
+		handedOff []int
+	}{
+		{PolicyDrain, []int{1, 2, 3, 4}, nil},
+		{PolicyHandOff, []int{1}, []int{2, 3, 4}},
+		{PolicyDrop, []int{1}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+
+			h := kmstest.New()
+
+			var (
+				mu        sync.Mutex
+				processed []int
+				handedOff []int
+			)
+
+			started := make(chan struct{}, 4)
+			release := make(chan struct{})
+
+			fn := func(ctx context.Context, item int) error {
+				started <- struct{}{}
+				<-release
+
+				mu.Lock()
+				processed = append(processed, item)
+				mu.Unlock()
+
+				return nil
+			}
+
+			opts := []Option{WithQueueSize(3), WithPolicy(tt.policy)}
+
+			if tt.policy == PolicyHandOff {
+				opts = append(opts, WithHandOff(func(ctx context.Context, queued []int) error {
+					handedOff = queued
+					return nil
+				}))
+			}
+
+			p := NewWithManager(h.Manager, "test", 1, fn, opts...)
+
+			for i := 1; i <= 4; i++ {
+
+				if err := p.Submit(context.Background(), i); err != nil {
+					t.Fatalf("Expected '%v' Got '%v'", nil, err)
+				}
+
+				// wait for the first item to be taken by the worker
+				if i == 1 {
+					<-started
+				}
+			}
+
+			if err := p.TrySubmit(5); err != ErrQueueFull {
+				t.Errorf("Expected '%v' Got '%v'", ErrQueueFull, err)
+			}
+
+			gauges := h.Stats().Gauges
+
+			if n := gauges["kmspool.queued:test"]; n != 3 {
+				t.Errorf("Expected '%d' queued Got '%d'", 3, n)
+			}
+
+			if n := gauges["kmspool.inflight:test"]; n != 1 {
+				t.Errorf("Expected '%d' in-flight Got '%d'", 1, n)
+			}
+
+			if n := h.InFlightCount(); n != 4 {
+				t.Errorf("Expected '%d' operations Got '%d'", 4, n)
+			}
+
+			h.Listen(false)
+			h.Shutdown(nil)
+			<-h.ShutdownInitiated()
+
+			if err := p.Submit(context.Background(), 5); err != ErrClosed {
+				t.Errorf("Expected '%v' Got '%v'", ErrClosed, err)
+			}
+
+			close(release)
+
+			kmstest.AssertDrainedWithin(t, h.Manager, time.Second)
+
+			select {
+			case <-p.Done():
+			case <-time.After(time.Second):
+				t.Fatalf("Expected the pool to be done")
+			}
+
+			if !reflect.DeepEqual(processed, tt.processed) {
+				t.Errorf("Expected '%v' Got '%v'", tt.processed, processed)
+			}
+
+			if !reflect.DeepEqual(handedOff, tt.handedOff) {
+				t.Errorf("Expected '%v' Got '%v'", tt.handedOff, handedOff)
+			}
+
+			if _, ok := h.Stats().Gauges["kmspool.queued:test"]; ok {
+				t.Errorf("Expected gauges to be unregistered once the pool is done")
+			}
+		})
+	}
+}
+
+func TestPoolSubmitBlocked(t *testing.T) {
+
+	h := kmstest.New()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	p := NewWithManager(h.Manager, "test", 1, func(ctx context.Context, item int) error {
+		<-release
+		return nil
+	}, WithQueueSize(0))
+
+	// the worker takes the first item, the second blocks
+	if err := p.Submit(context.Background(), 1); err != nil {
+		t.Fatalf("Expected '%v' Got '%v'", nil, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	if err := p.Submit(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected '%v' Got '%v'", context.DeadlineExceeded, err)
+	}
+
+	errs := make(chan error)
+
+	go func() {
+		errs <- p.Submit(context.Background(), 3)
+	}()
+
+	h.Listen(false)
+	h.Shutdown(nil)
+
+	if err := <-errs; err != ErrClosed {
+		t.Errorf("Expected '%v' Got '%v'", ErrClosed, err)
+	}
+
+	if n := h.InFlightCount(); n != 1 {
+		t.Errorf("Expected '%d' operations Got '%d'", 1, n)
+	}
+}
+
+func TestPoolHandOffMisconfigured(t *testing.T) {
+
+	h := kmstest.New()
+	fn := func(ctx context.Context, item int) error { return nil }
+
+	tests := []struct {
+		opt      Option
+		expected string
+	}{
+		{WithPolicy(PolicyHandOff), "kmspool: PolicyHandOff requires a hand-off callback, see WithHandOff()"},
+		{WithHandOff(func(ctx context.Context, queued []string) error { return nil }), "kmspool: hand-off item type does not match the pool's"},
+	}
+
+	for _, tt := range tests {
+
+		func() {
+			defer func() {
+				if r := recover(); r != tt.expected {
+					t.Errorf("Expected '%s' Got '%v'", tt.expected, r)
+				}
+			}()
+
+			NewWithManager(h.Manager, "misconfigured", 1, fn, tt.opt)
+		}()
+	}
+}
+
+func TestPoolReset(t *testing.T) {
+
+	h := kmstest.New()
+
+	p := NewWithManager(h.Manager, "test", 1, func(ctx context.Context, item int) error {
+		return nil
+	}, WithPolicy(PolicyDrop))
+
+	if err := h.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Submit(context.Background(), 1); err != nil {
+		t.Fatalf("Expected '%v' Got '%v'", nil, err)
+	}
+
+	h.Listen(false)
+	h.Shutdown(nil)
+
+	select {
+	case <-p.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the pool to be done after a shutdown following a reset")
+	}
+
+	if err := p.TrySubmit(2); err != ErrClosed {
+		t.Errorf("Expected '%v' Got '%v'", ErrClosed, err)
+	}
+
+	if _, ok := h.Stats().Gauges["kmspool.queued:test"]; ok {
+		t.Errorf("Expected gauges to be unregistered once the pool is done")
+	}
+
+	kmstest.AssertDrainedWithin(t, h.Manager, time.Second)
+}
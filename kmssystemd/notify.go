@@ -0,0 +1,213 @@
+// Package kmssystemd integrates kms with the systemd notify protocol, see sd_notify(3).
+//
+// READY=1 is sent when the application declares readiness, STOPPING=1 as soon as a shutdown
+// is initiated, STATUS= lines describing the drain progress while shutting down, RELOADING=1
+// followed by READY=1 around reloads and WATCHDOG=1 pings at half of WATCHDOG_USEC while the
+// process is running.
+//
+//	n, err := kmssystemd.New(kms.Default())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	n.Start()
+//
+//	// create listeners, connect to databases...
+//
+//	n.Ready()
+package kmssystemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-playground/kms"
+)
+
+// Option configures a Notifier during creation, see New()
+type Option func(*Notifier)
+
+// StatusInterval sets how often STATUS= lines are sent while draining.
+//
+// Default: 1 second
+func StatusInterval(d time.Duration) Option {
+	return func(n *Notifier) {
+		n.statusInterval = d
+	}
+}
+
+// Notifier speaks the systemd notify protocol on behalf of a kms.Manager. When the process was
+// not started by systemd, NOTIFY_SOCKET is not set, all notifications are silently dropped.
+type Notifier struct {
+	m              *kms.Manager
+	mu             sync.Mutex
+	conn           *net.UnixConn
+	watchdog       time.Duration
+	statusInterval time.Duration
+	start          sync.Once
+}
+
+// New returns a new Notifier for the provided kms.Manager configured from the NOTIFY_SOCKET,
+// WATCHDOG_USEC and WATCHDOG_PID environment variables.
+func New(m *kms.Manager, opts ...Option) (*Notifier, error) {
+
+	n := &Notifier{
+		m:              m,
+		statusInterval: time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	if addr := os.Getenv("NOTIFY_SOCKET"); addr != "" {
+
+		// abstract namespace socket
+		if addr[0] == '@' {
+			addr = "\x00" + addr[1:]
+		}
+
+		conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+		if err != nil {
+			return nil, fmt.Errorf("kmssystemd: connecting to notify socket: %w", err)
+		}
+
+		n.conn = conn
+	}
+
+	if usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64); err == nil && usec > 0 {
+
+		pid, err := strconv.Atoi(os.Getenv("WATCHDOG_PID"))
+
+		if err != nil || pid == os.Getpid() {
+			n.watchdog = time.Duration(usec) * time.Microsecond
+		}
+	}
+
+	return n, nil
+}
+
+// Enabled returns if the process was started with a systemd notify socket.
+func (n *Notifier) Enabled() bool {
+	return n.conn != nil
+}
+
+// WatchdogInterval returns the watchdog timeout configured by systemd, zero if not enabled.
+func (n *Notifier) WatchdogInterval() time.Duration {
+	return n.watchdog
+}
+
+// Notify sends the raw state eg. "RELOADING=1", multiple assignments are separated by newlines.
+func (n *Notifier) Notify(state string) error {
+
+	if n.conn == nil {
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, err := n.conn.Write([]byte(state))
+	return err
+}
+
+// Ready notifies systemd that the application has completed start up.
+func (n *Notifier) Ready() error {
+	return n.Notify("READY=1")
+}
+
+// Status sends a free form status line describing the application state.
+func (n *Notifier) Status(status string) error {
+	return n.Notify("STATUS=" + status)
+}
+
+// Start begins notifying systemd of the kms.Manager's lifecycle, STOPPING=1 once a shutdown is
+// initiated, STATUS= lines with the in-flight operation count while draining, RELOADING=1 and
+// READY=1 around reloads and WATCHDOG=1 pings while the process is running.
+func (n *Notifier) Start() {
+	n.start.Do(func() {
+		if n.conn != nil {
+			go n.run(n.m.Subscribe())
+		}
+	})
+}
+
+func (n *Notifier) run(sub *kms.Subscription) {
+
+	defer sub.Close()
+
+	log := n.m.Logger()
+
+	var ping <-chan time.Time
+
+	// systemd measures the watchdog in real time, so unlike kms' timers it ignores the Manager's Clock
+	if n.watchdog > 0 {
+		t := time.NewTicker(n.watchdog / 2)
+		defer t.Stop()
+		ping = t.C
+	}
+
+	notify := func(state string) {
+		if err := n.Notify(state); err != nil {
+			log.Error("kmssystemd: notify", "state", state, "error", err)
+		}
+	}
+
+	initiated := n.m.ShutdownInitiated()
+	complete := n.m.ShutdownComplete()
+
+	var status <-chan time.Time
+
+	for {
+		select {
+		case <-ping:
+			notify("WATCHDOG=1")
+
+		case <-initiated:
+			initiated = nil
+			notify("STOPPING=1")
+			notify(n.drainStatus())
+
+			t := time.NewTicker(n.statusInterval)
+			defer t.Stop()
+			status = t.C
+
+		case <-status:
+			notify(n.drainStatus())
+
+		case e := <-sub.Events():
+			switch e.Type {
+			case kms.EventReloadStarted:
+				notify("RELOADING=1")
+			case kms.EventReloadComplete:
+				if e.Err != nil {
+					notify("READY=1\nSTATUS=Reload failed")
+				} else {
+					notify("READY=1")
+				}
+			}
+
+		case <-complete:
+			notify("STATUS=Shutdown complete")
+			return
+		}
+	}
+}
+
+func (n *Notifier) drainStatus() string {
+	return fmt.Sprintf("STATUS=Draining, %d operation(s) in-flight", n.m.InFlightCount())
+}
+
+// Close closes the connection to the notify socket.
+func (n *Notifier) Close() error {
+
+	if n.conn == nil {
+		return nil
+	}
+
+	return n.conn.Close()
+}
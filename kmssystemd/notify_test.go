@@ -0,0 +1,119 @@
+package kmssystemd
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/kms"
+)
+
+func listenNotify(t *testing.T) *net.UnixConn {
+
+	addr := filepath.Join(t.TempDir(), "notify.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("NOTIFY_SOCKET", addr)
+
+	return conn
+}
+
+func expectState(t *testing.T, conn *net.UnixConn, prefix string) {
+
+	t.Helper()
+
+	b := make([]byte, 1024)
+	deadline := time.Now().Add(time.Second)
+
+	for {
+		conn.SetReadDeadline(deadline)
+
+		n, err := conn.Read(b)
+		if err != nil {
+			t.Fatalf("Expected '%s' Got '%v'", prefix, err)
+		}
+
+		if strings.HasPrefix(string(b[:n]), prefix) {
+			return
+		}
+	}
+}
+
+func TestNotifier(t *testing.T) {
+
+	conn := listenNotify(t)
+	defer conn.Close()
+
+	t.Setenv("WATCHDOG_USEC", "20000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	m := kms.New(kms.WithSignalFn(func() <-chan os.Signal { return make(chan os.Signal) }))
+
+	n, err := New(m, StatusInterval(time.Millisecond*10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+
+	if !n.Enabled() || n.WatchdogInterval() != time.Millisecond*20 {
+		t.Fatalf("Expected notifier to be enabled with watchdog")
+	}
+
+	n.Start()
+
+	if err = n.Ready(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectState(t, conn, "READY=1")
+	expectState(t, conn, "WATCHDOG=1")
+
+	m.OnReload("config", func(ctx context.Context) error { return nil })
+
+	if err = m.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectState(t, conn, "RELOADING=1")
+	expectState(t, conn, "READY=1")
+
+	op := m.Track("slow")
+
+	m.Listen(false)
+	m.Shutdown(nil)
+
+	expectState(t, conn, "STOPPING=1")
+	expectState(t, conn, "STATUS=Draining, 1 operation(s) in-flight")
+
+	op.Done()
+
+	expectState(t, conn, "STATUS=Shutdown complete")
+}
+
+func TestNotifierDisabled(t *testing.T) {
+
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	n, err := New(kms.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n.Start()
+
+	if n.Enabled() {
+		t.Errorf("Expected notifier to be disabled")
+	}
+
+	if err = n.Ready(); err != nil {
+		t.Errorf("Expected no error Got '%v'", err)
+	}
+}
@@ -0,0 +1,161 @@
+package kmstest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-playground/kms"
+)
+
+// Clock is a kms.Clock whose time only moves when Advance is called.
+type Clock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*timer
+}
+
+var _ kms.Clock = new(Clock)
+
+// NewClock returns a new Clock set to the provided time.
+func NewClock(now time.Time) *Clock {
+	c := &Clock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the Clock's current time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel receiving the Clock's time once it has been advanced by d.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer returns a kms.Timer which fires once the Clock has been advanced by d.
+func (c *Clock) NewTimer(d time.Duration) kms.Timer {
+
+	t := &timer{c: c, ch: make(chan time.Time, 1)}
+
+	c.mu.Lock()
+	c.schedule(t, d)
+	c.mu.Unlock()
+
+	return t
+}
+
+// Advance moves the Clock forward by d, firing all timers which expire in the meantime in order.
+func (c *Clock) Advance(d time.Duration) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].at.Before(c.timers[j].at)
+	})
+
+	n := 0
+
+	for _, t := range c.timers {
+		if !t.at.After(c.now) {
+			t.fire(t.at)
+			continue
+		}
+
+		c.timers[n] = t
+		n++
+	}
+
+	clear(c.timers[n:])
+	c.timers = c.timers[:n]
+	c.cond.Broadcast()
+}
+
+// Timers returns the number of active timers.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil blocks until at least n timers are active; useful for waiting for the code under
+// test to start waiting on the Clock before calling Advance.
+func (c *Clock) BlockUntil(n int) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// schedule must be called with mu held
+func (c *Clock) schedule(t *timer, d time.Duration) {
+
+	t.at = c.now.Add(d)
+
+	if d <= 0 {
+		t.fire(c.now)
+		return
+	}
+
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+}
+
+// unschedule must be called with mu held
+func (c *Clock) unschedule(t *timer) bool {
+
+	for i, ct := range c.timers {
+		if ct == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+
+	return false
+}
+
+type timer struct {
+	c  *Clock
+	ch chan time.Time
+	at time.Time
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *timer) Stop() bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	return t.c.unschedule(t)
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+
+	active := t.c.unschedule(t)
+	t.c.schedule(t, d)
+
+	return active
+}
+
+// fire delivers the time without blocking, the same as time.Timer
+func (t *timer) fire(now time.Time) {
+	select {
+	case t.ch <- now:
+	default:
+	}
+}
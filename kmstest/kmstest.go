@@ -0,0 +1,169 @@
+// Package kmstest provides utilities for testing code built on kms without sending real
+// signals, exiting the process or sleeping.
+//
+//	h := kmstest.New()
+//
+//	op := h.Track("request")
+//	h.ListenTimeout(false, time.Minute)
+//
+//	h.Signals.Send(syscall.SIGTERM)
+//	h.Clock.BlockUntil(1)
+//	h.Clock.Advance(time.Minute)
+//
+//	if code, ok := h.Exits.Wait(time.Second); !ok || code != 1 {
+//		t.Fatalf("expected timeout exit code")
+//	}
+package kmstest
+
+import (
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-playground/kms"
+)
+
+// Signals is a fake signal source, see kms.SetSignalFn()
+type Signals struct {
+	c chan os.Signal
+}
+
+// NewSignals returns a new fake signal source.
+func NewSignals() *Signals {
+	return &Signals{c: make(chan os.Signal, 8)}
+}
+
+// SignalFn returns the kms.SignalFn delivering the signals passed to Send.
+func (s *Signals) SignalFn() kms.SignalFn {
+	return func() <-chan os.Signal {
+		return s.c
+	}
+}
+
+// Send delivers the signal as if it had been received by the process.
+func (s *Signals) Send(sig os.Signal) {
+	s.c <- sig
+}
+
+// Exits records the exit codes passed to the exit function instead of exiting the process,
+// see kms.WithExitFunc()
+type Exits struct {
+	mu    sync.Mutex
+	codes []int
+	c     chan int
+}
+
+// NewExits returns a new exit recorder.
+func NewExits() *Exits {
+	return &Exits{c: make(chan int, 8)}
+}
+
+// ExitFunc returns the exit function recording the exit codes.
+func (e *Exits) ExitFunc() func(int) {
+	return func(code int) {
+
+		e.mu.Lock()
+		e.codes = append(e.codes, code)
+		e.mu.Unlock()
+
+		select {
+		case e.c <- code:
+		default:
+		}
+	}
+}
+
+// Codes returns all exit codes recorded so far.
+func (e *Exits) Codes() []int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]int(nil), e.codes...)
+}
+
+// Wait waits up to timeout, in real time, for the next exit and returns it's code; ok is false
+// if the process did not exit in time.
+func (e *Exits) Wait(timeout time.Duration) (code int, ok bool) {
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case code = <-e.c:
+		return code, true
+	case <-t.C:
+		return 0, false
+	}
+}
+
+// Harness is a kms.Manager wired with a fake signal source, exit recorder and clock.
+type Harness struct {
+	*kms.Manager
+	Signals *Signals
+	Exits   *Exits
+	Clock   *Clock
+}
+
+// New returns a new Harness; the Manager's logging is discarded and the provided options are
+// applied last so that they may override the fakes.
+func New(opts ...kms.Option) *Harness {
+
+	h := &Harness{
+		Signals: NewSignals(),
+		Exits:   NewExits(),
+		Clock:   NewClock(time.Now()),
+	}
+
+	opts = append([]kms.Option{
+		kms.WithSignalFn(h.Signals.SignalFn()),
+		kms.WithExitFunc(h.Exits.ExitFunc()),
+		kms.WithClock(h.Clock),
+		kms.WithLogger(slog.New(slog.DiscardHandler)),
+	}, opts...)
+
+	h.Manager = kms.New(opts...)
+
+	return h
+}
+
+// AssertDrainedWithin fails the test if the Manager's shutdown does not complete within d, in
+// real time, reporting the operations still in-flight.
+func AssertDrainedWithin(tb testing.TB, m *kms.Manager, d time.Duration) {
+
+	tb.Helper()
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-m.ShutdownComplete():
+	case <-t.C:
+		tb.Fatalf("kmstest: shutdown did not complete within %s, %s", d, describe(m.InFlight()))
+	}
+}
+
+// AssertNoInFlight fails the test if the Manager has any in-flight operations.
+func AssertNoInFlight(tb testing.TB, m *kms.Manager) {
+
+	tb.Helper()
+
+	if ops := m.InFlight(); len(ops) > 0 {
+		tb.Fatalf("kmstest: expected no in-flight operations, %s", describe(ops))
+	}
+}
+
+func describe(ops []kms.OperationInfo) string {
+
+	if len(ops) == 0 {
+		return "no operations in-flight"
+	}
+
+	s := "in-flight:"
+
+	for _, op := range ops {
+		s += "\n\t" + op.String()
+	}
+
+	return s
+}
@@ -0,0 +1,199 @@
+package kmstest
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-playground/kms"
+)
+
+func TestHarnessTimeout(t *testing.T) {
+
+	h := New()
+
+	op := h.Track("request")
+	h.ListenTimeout(false, time.Minute)
+
+	h.Signals.Send(syscall.SIGTERM)
+
+	// wait for the timeout to be scheduled before advancing past it
+	h.Clock.BlockUntil(1)
+	h.Clock.Advance(time.Minute)
+
+	if code, ok := h.Exits.Wait(time.Second); !ok || code != 1 {
+		t.Fatalf("Expected exit code '%d' Got '%d' '%t'", 1, code, ok)
+	}
+
+	op.Done()
+
+	AssertDrainedWithin(t, h.Manager, time.Second)
+	AssertNoInFlight(t, h.Manager)
+}
+
+func TestHarnessLameDuck(t *testing.T) {
+
+	h := New()
+
+	h.SetLameDuck(time.Second * 30)
+	h.ListenTimeout(false, time.Minute)
+
+	h.Signals.Send(syscall.SIGTERM)
+
+	// the timeout and lame-duck period
+	h.Clock.BlockUntil(2)
+
+	select {
+	case <-h.DrainStarted():
+		t.Fatalf("Expected draining to wait for the lame-duck period")
+	default:
+	}
+
+	h.Clock.Advance(time.Second * 30)
+
+	AssertDrainedWithin(t, h.Manager, time.Second)
+
+	if codes := h.Exits.Codes(); len(codes) != 0 {
+		t.Errorf("Expected no exit Got '%v'", codes)
+	}
+
+	if d := h.Stats().DrainDuration; d != 0 {
+		t.Errorf("Expected drain to take no simulated time Got '%s'", d)
+	}
+}
+
+func TestClock(t *testing.T) {
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClock(start)
+
+	t1 := c.NewTimer(time.Second)
+	t2 := c.NewTimer(time.Second * 2)
+
+	if !t2.Stop() || t2.Stop() {
+		t.Errorf("Expected only the first Stop to stop the timer")
+	}
+
+	c.Advance(time.Second)
+
+	select {
+	case now := <-t1.C():
+		if !now.Equal(start.Add(time.Second)) {
+			t.Errorf("Expected '%s' Got '%s'", start.Add(time.Second), now)
+		}
+	default:
+		t.Fatalf("Expected timer to fire")
+	}
+
+	if t1.Reset(time.Second) {
+		t.Errorf("Expected expired timer to be inactive")
+	}
+
+	c.Advance(time.Millisecond * 999)
+
+	select {
+	case <-t1.C():
+		t.Fatalf("Expected timer not to fire early")
+	default:
+	}
+
+	c.Advance(time.Millisecond)
+
+	select {
+	case <-t1.C():
+	default:
+		t.Fatalf("Expected reset timer to fire")
+	}
+
+	if c.Timers() != 0 {
+		t.Errorf("Expected '%d' timers Got '%d'", 0, c.Timers())
+	}
+}
+
+func TestHarnessEscalation(t *testing.T) {
+
+	h := New(kms.WithEscalation(kms.Escalation{
+		ForceCloseAfter: time.Second * 10,
+		LastGaspAfter:   time.Second * 10,
+	}))
+
+	sub := h.Subscribe()
+	defer sub.Close()
+
+	op := h.Track("stuck")
+	defer op.Done()
+
+	h.ListenTimeout(false, time.Minute)
+	h.Signals.Send(syscall.SIGTERM)
+
+	steps := []struct {
+		advance time.Duration
+		event   kms.EventType
+	}{
+		{time.Minute, kms.EventHardStop},
+		{time.Second * 10, kms.EventForceClose},
+		{time.Second * 10, kms.EventLastGasp},
+	}
+
+	for _, step := range steps {
+
+		h.Clock.BlockUntil(1)
+		h.Clock.Advance(step.advance)
+
+		for e := range sub.Events() {
+			if e.Type == step.event {
+				break
+			}
+		}
+	}
+
+	if code, ok := h.Exits.Wait(time.Second); !ok || code != 1 {
+		t.Fatalf("Expected exit code '%d' Got '%d' '%t'", 1, code, ok)
+	}
+}
+
+func TestHarnessHookTimeout(t *testing.T) {
+
+	h := New()
+
+	h.OnShutdown(kms.PhaseClose, "hung", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, kms.HookTimeout(time.Second*5))
+
+	h.Listen(false)
+	h.Shutdown(nil)
+
+	h.Clock.BlockUntil(1)
+	h.Clock.Advance(time.Second * 5)
+
+	AssertDrainedWithin(t, h.Manager, time.Second)
+
+	errs := h.HookErrors()
+
+	if len(errs) != 1 || !errors.Is(errs[0], context.DeadlineExceeded) {
+		t.Errorf("Expected '%v' Got '%v'", context.DeadlineExceeded, errs)
+	}
+}
+
+func TestOperationAge(t *testing.T) {
+
+	h := New()
+
+	op := h.Track("request")
+	defer op.Done()
+
+	h.Clock.Advance(time.Minute)
+
+	ops := h.InFlight()
+
+	if len(ops) != 1 || ops[0].Age != time.Minute {
+		t.Fatalf("Expected age '%s' Got '%v'", time.Minute, ops)
+	}
+
+	if s := ops[0].String(); s != "request (running for 1m0s)" {
+		t.Errorf("Expected '%s' Got '%s'", "request (running for 1m0s)", s)
+	}
+}
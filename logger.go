@@ -0,0 +1,51 @@
+package kms
+
+import (
+	"log/slog"
+	"os"
+)
+
+// WithLogger sets the structured logger the Manager reports shutdown progress to,
+// see SetLogger()
+func WithLogger(logger *slog.Logger) Option {
+	return func(m *Manager) {
+		m.SetLogger(logger)
+	}
+}
+
+// SetLogger sets the structured logger the Manager reports shutdown progress to eg.
+// signal received, drain started, hard shutdown requested, timeout and completion.
+// Passing nil silences all output.
+//
+// Default: text output to os.Stderr
+func (m *Manager) SetLogger(logger *slog.Logger) {
+
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	m.logger.Store(logger)
+}
+
+// Logger returns the structured logger used by the Manager; useful for helpers, such as
+// kmsnet, that need to report errors as part of the Manager's lifecycle.
+func (m *Manager) Logger() *slog.Logger {
+	return m.logger.Load().(*slog.Logger)
+}
+
+func defaultLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, nil)).With("component", "kms")
+}
+
+// SetLogger sets the structured logger the package reports shutdown progress to.
+// Passing nil silences all output.
+//
+// Default: text output to os.Stderr
+func SetLogger(logger *slog.Logger) {
+	defaultManager.SetLogger(logger)
+}
+
+// Logger returns the structured logger used by the package
+func Logger() *slog.Logger {
+	return defaultManager.Logger()
+}
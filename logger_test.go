@@ -0,0 +1,68 @@
+package kms
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestLogger(t *testing.T) {
+
+	buff := new(syncBuffer)
+	sig := make(chan os.Signal, 1)
+
+	m := New(
+		WithSignalFn(chanSignalFn(sig)),
+		WithLogger(slog.New(slog.NewJSONHandler(buff, nil))),
+	)
+
+	m.ListenTimeout(false, time.Minute)
+	sig <- syscall.SIGTERM
+
+	select {
+	case <-m.ShutdownComplete():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected shutdown to complete")
+	}
+
+	out := buff.String()
+
+	for _, msg := range []string{`"msg":"signal received"`, `"signal":"terminated"`, `"msg":"drain started"`, `"msg":"shutdown complete"`, `"duration"`} {
+		if !strings.Contains(out, msg) {
+			t.Errorf("Expected output to contain '%s' Got '%s'", msg, out)
+		}
+	}
+}
+
+func TestLoggerSilent(t *testing.T) {
+
+	m := New(WithLogger(nil))
+
+	if m.Logger() == nil {
+		t.Fatalf("Expected non-nil logger")
+	}
+
+	m.Logger().Info("discarded")
+}
@@ -0,0 +1,399 @@
+package kms
+
+import (
+	"container/list"
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Manager is an independent shutdown domain; it has it's own signal source,
+// in-flight operation tracking and notification channels.
+//
+// The package level functions operate on a default Manager, see Default(), but
+// multiple Managers can be created using New() and live side by side within
+// the same process.
+type Manager struct {
+	wg *sync.WaitGroup
+
+	opsMu sync.Mutex
+	opID  uint64
+	ops   map[uint64]*operation
+	anon  *list.List // anonymous operations started via Wait(), oldest first
+
+	// read without opsMu so that Stats() never contends with Wait/Done
+	waits atomic.Uint64
+	dones atomic.Uint64
+
+	// set once Done() has been called without a token, from then on anonymous operations are
+	// no longer checked for leaks as any of them may be completed by it.
+	anonDone atomic.Bool
+
+	mu       sync.Mutex
+	hooks    [numPhases][]hook
+	hookErrs []error
+	reason   error
+	checks   []*readinessCheck
+	gauges   map[string]*gauge
+	goErrs   []error
+
+	hookID       uint64 // identifies hooks which may be unregistered
+	forceClosers []hook
+	lastGasps    []hook
+
+	reloaders     []hook
+	reloadSig     os.Signal
+	reloadTimeout time.Duration
+
+	subsMu sync.RWMutex
+	subs   map[*Subscription]struct{}
+
+	reloadMu   sync.Mutex
+	reloadCur  *reloadRun
+	reloadNext *reloadRun
+
+	// note only atomic.Value for tests especially "go test -race"
+	notify       atomic.Value // chan struct{}
+	trigger      atomic.Value // chan struct{}
+	done         atomic.Value // chan struct{}
+	exitFunc     atomic.Value // os.Exit aka func(int)
+	hardShutdown atomic.Value // bool
+	sigFn        atomic.Value // SignalFn
+	ctx          atomic.Value // *shutdownContext
+	hardCtx      atomic.Value // *shutdownContext
+	logger       atomic.Value // *slog.Logger
+	exitPolicy   atomic.Value // ExitPolicy
+	diagnostics  atomic.Value // diagnostics
+	drain        atomic.Value // chan struct{}
+	state        atomic.Value // State
+	lameDuck     atomic.Value // time.Duration
+	shutdownAt   atomic.Value // time.Time
+	drainAt      atomic.Value // time.Time
+	drainDur     atomic.Value // time.Duration
+	stuckAge     atomic.Value // time.Duration
+	leakCheck    atomic.Value // bool
+	escalation   atomic.Value // Escalation
+	clock        atomic.Value // Clock
+	goShutdown   atomic.Value // bool
+	stop         atomic.Value // chan struct{}, closed by Reset
+}
+
+var _ KillingMeSoftly = new(Manager)
+
+// Option configures a Manager during creation, see New()
+type Option func(*Manager)
+
+// WithSignalFn sets the SignalFn used by the Manager, see SetSignalFn()
+func WithSignalFn(fn SignalFn) Option {
+	return func(m *Manager) {
+		m.SetSignalFn(fn)
+	}
+}
+
+// WithHardShutdown sets whether a second signal should cause a hard shutdown,
+// see AllowSignalHardShutdown()
+func WithHardShutdown(allow bool) Option {
+	return func(m *Manager) {
+		m.AllowSignalHardShutdown(allow)
+	}
+}
+
+// WithExitFunc sets the function called to terminate the process when a hard
+// shutdown occurs, or once complete according to the ExitPolicy.
+//
+// Default: os.Exit
+func WithExitFunc(fn func(int)) Option {
+	return func(m *Manager) {
+		m.exitFunc.Store(fn)
+	}
+}
+
+// New returns a new Manager instance configured with the provided options.
+func New(opts ...Option) *Manager {
+
+	m := &Manager{
+		wg:   new(sync.WaitGroup),
+		ops:  make(map[uint64]*operation),
+		anon: list.New(),
+
+		reloadSig:     defaultReloadSignal(),
+		reloadTimeout: time.Second * 30,
+	}
+
+	m.arm()
+	m.exitFunc.Store(os.Exit)
+	m.logger.Store(defaultLogger())
+	m.exitPolicy.Store(DefaultExitPolicy())
+	m.diagnostics.Store(diagnostics{})
+	m.lameDuck.Store(time.Duration(0))
+	m.stuckAge.Store(time.Duration(0))
+	m.leakCheck.Store(false)
+	m.escalation.Store(DefaultEscalation())
+	m.SetClock(RealClock())
+	m.goShutdown.Store(false)
+	m.AllowSignalHardShutdown(true)
+	m.SetSignalFn(NewSignalRouter(m).SignalFn())
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// AllowSignalHardShutdown allows you to set whether the application
+// should allow hard shutdown of the application if two signals for shutdown
+// should cause a hard shutdown. eg. user running application from the command
+// line types CTRL + C, the application begins to shut down gracefully, if the
+// user types another CTRL + C should the application shut down hard?
+//
+// Each additional signal takes the next step of the escalation ladder, see SetEscalation().
+//
+// Default: true
+func (m *Manager) AllowSignalHardShutdown(allow bool) {
+	m.hardShutdown.Store(allow)
+}
+
+// SetSignalFn allows registering of a custom signal function
+// if you wish to listen for different signals, or even your own custom
+// logic not related to signals. By default this function listens for
+// syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP
+func (m *Manager) SetSignalFn(fn SignalFn) {
+	m.sigFn.Store(fn)
+}
+
+// ShutdownInitiated returns a notification channel for the Manager which will be
+// closed/notified once a termination signal is received.
+func (m *Manager) ShutdownInitiated() <-chan struct{} {
+	return m.notify.Load().(chan struct{})
+}
+
+// ShutdownComplete returns a notification channel for the Manager which will be
+// closed/notified once termination is imminent.
+func (m *Manager) ShutdownComplete() <-chan struct{} {
+	return m.done.Load().(chan struct{})
+}
+
+// Wait signifies that your application is busy performing an operation.
+//
+// best to chain using defer m.Wait().Done(), or use Track() to name the operation.
+func (m *Manager) Wait() KillingMeSoftly {
+	o := m.track("kms.Wait", nil, true)
+	m.watchLeak(o)
+	return o
+}
+
+// Done signifies that your application is done performing an operation. it is different from
+// the chained version as it does not need to be connected the the wait object, the oldest
+// outstanding operation started using Wait() is marked as done. A Wait() token whose operation
+// was completed this way marks another outstanding one as done instead.
+//
+// Calling Done without an outstanding operation panics with an error wrapping ErrUnbalancedDone.
+func (m *Manager) Done() {
+	m.anonDone.Store(true)
+	m.finishAnonymous()
+}
+
+// Listen sets up signals to listen for interrupt or kill signals
+// in an attempt to wait for all operations to complete before letting
+// the process die.
+func (m *Manager) Listen(block bool) {
+	m.listen(block, 0)
+}
+
+// ListenTimeout sets up signals to listen for interrupt or kill signals
+// in an attempt to wait for all operations to complete before letting
+// the process die.
+//
+// the wait duration is how long to wait before forcefully shutting everything down, see
+// SetEscalation() for the steps taken once it expires.
+func (m *Manager) ListenTimeout(block bool, wait time.Duration) {
+	m.listen(block, wait)
+}
+
+// listen is the shared implementation of Listen and ListenTimeout, a wait
+// duration of zero means wait indefinitely.
+func (m *Manager) listen(block bool, wait time.Duration) {
+
+	s := m.sigFn.Load().(SignalFn)()
+	done := m.done.Load().(chan struct{})
+	notify := m.notify.Load().(chan struct{})
+	trigger := m.trigger.Load().(chan struct{})
+	drain := m.drain.Load().(chan struct{})
+	ctx := m.ctx.Load().(*shutdownContext)
+	hardCtx := m.hardCtx.Load().(*shutdownContext)
+	exit := m.exitFunc.Load().(func(int))
+	clock := m.Clock()
+
+	stop := m.stop.Load().(chan struct{})
+
+	if age := m.stuckAge.Load().(time.Duration); age > 0 {
+		go m.watchdog(age, notify, stop)
+	}
+
+	go func() {
+
+		var reason error
+
+		select {
+		case sig := <-s:
+			// the signal may have raced a Reset, in which case it belongs to no lifecycle
+			if reason = m.recordReason(trigger, &SignalError{Signal: sig}); reason == nil {
+				return
+			}
+			m.emit(Event{Type: EventSignalReceived, Signal: sig})
+		case <-trigger:
+			reason = m.ShutdownReason()
+		case <-stop:
+			return
+		}
+
+		lameDuck := m.lameDuck.Load().(time.Duration)
+
+		var timeout <-chan time.Time
+
+		if wait > 0 {
+			deadline := clock.Now().Add(lameDuck + wait)
+			ctx.setDeadline(deadline)
+			hardCtx.setDeadline(deadline)
+			timeout = clock.After(lameDuck + wait)
+		}
+
+		if lameDuck > 0 {
+			m.state.Store(StateLameDuck)
+		} else {
+			m.state.Store(StateDraining)
+		}
+
+		start := clock.Now()
+		m.shutdownAt.Store(start)
+
+		close(notify)
+		ctx.cancel(reason)
+		m.emit(Event{Type: EventShutdownInitiated, Time: start, Reason: reason, InFlight: m.inFlightCount()})
+
+		var second <-chan os.Signal
+
+		if m.hardShutdown.Load().(bool) {
+			// listen for another signal, if another happens.. force shutdown
+			second = s
+		}
+
+		go func() {
+			for {
+				// once done a signal belongs to the next lifecycle, eg. after Reset, and must not
+				// be consumed here by a select racing the closed done channel.
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				select {
+				case <-timeout:
+					m.emit(Event{Type: EventTimeout, Duration: wait, InFlight: m.inFlightCount()})
+					m.reportInFlight()
+					m.writeDiagnostics(reason)
+					m.escalate(OutcomeTimeout, reason, second, done)
+					return
+				case sig, ok := <-second:
+					// a closed signal channel is not a signal
+					if !ok {
+						second = nil
+						continue
+					}
+					m.emit(Event{Type: EventHardShutdownRequested, Signal: sig})
+					m.escalate(OutcomeForced, &SignalError{Signal: sig}, second, done)
+					return
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		if lameDuck > 0 {
+			m.emit(Event{Type: EventLameDuckStarted, Duration: lameDuck})
+			<-clock.After(lameDuck)
+			m.state.Store(StateDraining)
+		}
+
+		drainAt := clock.Now()
+		m.drainAt.Store(drainAt)
+		close(drain)
+
+		deadline, _ := ctx.Deadline()
+		hookCtx := context.WithValue(context.Background(), reasonKey{}, reason)
+
+		m.runPhase(hookCtx, PhasePreDrain, deadline)
+
+		m.emit(Event{Type: EventDrainStarted, Duration: wait, InFlight: m.inFlightCount()})
+
+		drained := make(chan struct{})
+
+		go func() {
+			m.runPhase(hookCtx, PhaseDrain, deadline)
+			close(drained)
+		}()
+
+		m.wg.Wait()
+		<-drained
+		m.drainDur.Store(clock.Now().Sub(drainAt))
+
+		m.runPhase(hookCtx, PhaseClose, deadline)
+		m.runPhase(hookCtx, PhaseFinal, deadline)
+
+		m.state.Store(StateStopped)
+		m.emit(Event{Type: EventShutdownComplete, Duration: clock.Now().Sub(start)})
+		hardCtx.cancel(nil)
+		close(done)
+	}()
+
+	if block {
+		// a Reset before the shutdown was initiated stops this Listen, so it returns rather than
+		// waiting on a lifecycle that will never complete.
+		select {
+		case <-done:
+		case <-stop:
+			return
+		}
+
+		if m.exitPolicy.Load().(ExitPolicy).ExitOnComplete {
+			exit(m.ExitCode())
+		}
+	}
+}
+
+// forceExit terminates the process with the exit code for the outcome, the hard
+// stop context is always cancelled before exiting.
+func (m *Manager) forceExit(outcome Outcome, reason error) {
+
+	code := m.exitPolicy.Load().(ExitPolicy).Code(outcome, reason)
+
+	m.emit(Event{Type: EventExiting, Reason: reason, Outcome: outcome, Code: code})
+	m.hardCtx.Load().(*shutdownContext).cancel(nil)
+	m.exitFunc.Load().(func(int))(code)
+}
+
+// reportInFlight logs the operations still outstanding, used when the shutdown times out.
+func (m *Manager) reportInFlight() {
+
+	log := m.Logger()
+	now := m.Clock().Now()
+
+	for _, op := range m.InFlight() {
+		log.Warn("operation still in-flight", "operation", op.Name, "labels", op.Labels, "running_for", now.Sub(op.Started), "stack", op.Stack)
+	}
+}
+
+// signalName returns the name of the signal, a custom SignalFn may close it's channel
+// resulting in a nil signal.
+func signalName(sig os.Signal) string {
+
+	if sig == nil {
+		return "<nil>"
+	}
+
+	return sig.String()
+}
@@ -0,0 +1,90 @@
+package kms
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func chanSignalFn(c chan os.Signal) SignalFn {
+	return func() <-chan os.Signal {
+		return c
+	}
+}
+
+func TestManagerIndependent(t *testing.T) {
+
+	sig1 := make(chan os.Signal, 1)
+	sig2 := make(chan os.Signal, 1)
+
+	m1 := New(WithSignalFn(chanSignalFn(sig1)))
+	m2 := New(WithSignalFn(chanSignalFn(sig2)))
+
+	m1.Listen(false)
+	m2.Listen(false)
+
+	m2.Wait()
+
+	sig1 <- syscall.SIGTERM
+
+	select {
+	case <-m1.ShutdownComplete():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected Manager 1 to complete shutdown")
+	}
+
+	select {
+	case <-m2.ShutdownInitiated():
+		t.Fatalf("Expected Manager 2 to be unaffected by Manager 1 shutdown")
+	default:
+	}
+
+	sig2 <- syscall.SIGTERM
+
+	select {
+	case <-m2.ShutdownInitiated():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected Manager 2 to initiate shutdown")
+	}
+
+	select {
+	case <-m2.ShutdownComplete():
+		t.Fatalf("Expected Manager 2 to wait for outstanding operation")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	m2.Done()
+
+	select {
+	case <-m2.ShutdownComplete():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected Manager 2 to complete shutdown")
+	}
+}
+
+func TestManagerHardShutdown(t *testing.T) {
+
+	sig := make(chan os.Signal, 2)
+	exited := make(chan int, 1)
+
+	m := New(
+		WithSignalFn(chanSignalFn(sig)),
+		WithExitFunc(func(code int) { exited <- code }),
+	)
+
+	m.Wait()
+	m.ListenTimeout(false, time.Minute)
+
+	sig <- syscall.SIGINT
+	sig <- syscall.SIGINT
+
+	select {
+	case code := <-exited:
+		if code != 1 {
+			t.Errorf("Expected '%d' Got '%d'", 1, code)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected hard shutdown on second signal")
+	}
+}
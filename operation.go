@@ -0,0 +1,291 @@
+package kms
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+const maxStackDepth = 32
+
+// ErrUnbalancedDone is the error Done panics with when called more times than
+// there are outstanding operations.
+var ErrUnbalancedDone = errors.New("kms: unbalanced Done")
+
+// operation is the internal record of an in-flight operation, it is kept separate from the
+// Operation token handed to callers.
+type operation struct {
+	id      uint64
+	name    string
+	labels  map[string]string
+	started time.Time
+	pcs     []uintptr
+	anon    *list.Element // non-nil for anonymous operations started via Wait()
+	taken   bool          // completed by other than it's own token, guarded by opsMu
+	stuck   bool          // reported by the watchdog, guarded by opsMu
+}
+
+// OperationInfo describes an in-flight operation, see InFlight()
+type OperationInfo struct {
+	Name    string
+	Labels  map[string]string
+	Started time.Time
+
+	// Age is how long the operation had been running when it was described, measured
+	// using the Manager's Clock.
+	Age time.Duration
+
+	// Stack is the formatted stack of the caller that started the operation.
+	Stack string
+}
+
+// String returns a single line description of the operation.
+func (o OperationInfo) String() string {
+
+	var sb strings.Builder
+
+	sb.WriteString(o.Name)
+
+	if len(o.Labels) > 0 {
+
+		keys := make([]string, 0, len(o.Labels))
+		for k := range o.Labels {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			sb.WriteString(" ")
+			sb.WriteString(k)
+			sb.WriteString("=")
+			sb.WriteString(o.Labels[k])
+		}
+	}
+
+	fmt.Fprintf(&sb, " (running for %s)", o.Age.Round(time.Millisecond))
+
+	return sb.String()
+}
+
+// Operation is the token representing an in-flight operation, see Track()
+type Operation struct {
+	m  *Manager
+	op *operation
+}
+
+var _ KillingMeSoftly = new(Operation)
+
+// Name returns the name the operation was started with.
+func (o *Operation) Name() string {
+	return o.op.name
+}
+
+// Done signifies that the operation has completed.
+//
+// Calling Done more than once for the same operation panics with an error
+// wrapping ErrUnbalancedDone naming the operation.
+func (o *Operation) Done() {
+	o.m.finish(o.op)
+}
+
+// Track signifies that your application is busy performing the named operation; the
+// returned Operation records the name, labels, start time and caller stack and is
+// visible via InFlight() until Done is called.
+//
+// labels are key value pairs eg. Track("db.query", "table", "users")
+//
+// best to chain using defer m.Track("name").Done()
+func (m *Manager) Track(name string, labels ...string) *Operation {
+	o := m.track(name, labels, false)
+	m.watchLeak(o)
+	return o
+}
+
+func (m *Manager) track(name string, labels []string, anonymous bool) *Operation {
+
+	op := &operation{
+		name:    name,
+		started: m.Clock().Now(),
+	}
+
+	if len(labels) > 0 {
+
+		op.labels = make(map[string]string, (len(labels)+1)/2)
+
+		for i := 0; i < len(labels); i += 2 {
+			if i+1 < len(labels) {
+				op.labels[labels[i]] = labels[i+1]
+			} else {
+				op.labels[labels[i]] = ""
+			}
+		}
+	}
+
+	var pcs [maxStackDepth]uintptr
+
+	// skip runtime.Callers, track and the public caller of track
+	n := runtime.Callers(3, pcs[:])
+	op.pcs = append([]uintptr(nil), pcs[:n]...)
+
+	// counted before the operation is visible so that dones can never exceed waits
+	m.waits.Add(1)
+
+	m.opsMu.Lock()
+
+	m.opID++
+	op.id = m.opID
+	m.ops[op.id] = op
+
+	if anonymous {
+		op.anon = m.anon.PushBack(op)
+	}
+
+	m.wg.Add(1)
+	m.opsMu.Unlock()
+
+	return &Operation{m: m, op: op}
+}
+
+func (m *Manager) finish(op *operation) {
+
+	m.opsMu.Lock()
+
+	if _, ok := m.ops[op.id]; !ok {
+
+		// anonymous operations are interchangeable, a Wait() token whose operation was completed
+		// by Done() completes another in it's place, once.
+		if !op.taken || m.anon.Len() == 0 {
+			m.opsMu.Unlock()
+			panic(fmt.Errorf("%w: operation %q started at %s is already done", ErrUnbalancedDone, op.name, op.started.Format(time.RFC3339Nano)))
+		}
+
+		// the other's token may in turn complete another
+		op.taken = false
+		op = m.anon.Front().Value.(*operation)
+		op.taken = true
+	}
+
+	m.remove(op)
+	m.opsMu.Unlock()
+	m.dones.Add(1)
+	m.wg.Done()
+}
+
+// finishAnonymous completes the oldest outstanding operation started via Wait()
+func (m *Manager) finishAnonymous() {
+
+	m.opsMu.Lock()
+
+	e := m.anon.Front()
+	if e == nil {
+		m.opsMu.Unlock()
+		panic(fmt.Errorf("%w: no outstanding operations started using Wait()", ErrUnbalancedDone))
+	}
+
+	op := e.Value.(*operation)
+	op.taken = true
+
+	m.remove(op)
+	m.opsMu.Unlock()
+	m.dones.Add(1)
+	m.wg.Done()
+}
+
+// remove must be called with opsMu held
+func (m *Manager) remove(op *operation) {
+
+	delete(m.ops, op.id)
+
+	if op.anon != nil {
+		m.anon.Remove(op.anon)
+	}
+}
+
+func (m *Manager) inFlightCount() int {
+	m.opsMu.Lock()
+	defer m.opsMu.Unlock()
+	return len(m.ops)
+}
+
+// InFlight returns a description of all currently in-flight operations, oldest first.
+func (m *Manager) InFlight() []OperationInfo {
+
+	m.opsMu.Lock()
+
+	ops := make([]*operation, 0, len(m.ops))
+	for _, op := range m.ops {
+		ops = append(ops, op)
+	}
+
+	m.opsMu.Unlock()
+
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].id < ops[j].id
+	})
+
+	now := m.Clock().Now()
+	infos := make([]OperationInfo, len(ops))
+
+	for i, op := range ops {
+		infos[i] = op.info(now)
+	}
+
+	return infos
+}
+
+// info describes the operation as of now, the Manager's Clock time.
+func (op *operation) info(now time.Time) OperationInfo {
+
+	var sb strings.Builder
+
+	frames := runtime.CallersFrames(op.pcs)
+
+	for len(op.pcs) > 0 {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+
+		if !more {
+			break
+		}
+	}
+
+	var labels map[string]string
+
+	if op.labels != nil {
+		labels = make(map[string]string, len(op.labels))
+		for k, v := range op.labels {
+			labels[k] = v
+		}
+	}
+
+	return OperationInfo{
+		Name:    op.name,
+		Labels:  labels,
+		Started: op.started,
+		Age:     now.Sub(op.started),
+		Stack:   sb.String(),
+	}
+}
+
+// Track signifies that your application is busy performing the named operation; the
+// returned Operation records the name, labels, start time and caller stack and is
+// visible via InFlight() until Done is called.
+//
+// labels are key value pairs eg. kms.Track("db.query", "table", "users")
+//
+// best to chain using defer kms.Track("name").Done()
+func Track(name string, labels ...string) *Operation {
+	o := defaultManager.track(name, labels, false)
+	defaultManager.watchLeak(o)
+	return o
+}
+
+// InFlight returns a description of all currently in-flight operations, oldest first.
+func InFlight() []OperationInfo {
+	return defaultManager.InFlight()
+}
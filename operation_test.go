@@ -0,0 +1,141 @@
+package kms
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTrack(t *testing.T) {
+
+	m := New()
+
+	op := m.Track("db.query", "table", "users")
+	m.Wait()
+
+	ops := m.InFlight()
+	if len(ops) != 2 {
+		t.Fatalf("Expected '%d' Got '%d'", 2, len(ops))
+	}
+
+	if ops[0].Name != "db.query" {
+		t.Errorf("Expected '%s' Got '%s'", "db.query", ops[0].Name)
+	}
+
+	if ops[0].Labels["table"] != "users" {
+		t.Errorf("Expected '%s' Got '%s'", "users", ops[0].Labels["table"])
+	}
+
+	if !strings.Contains(ops[0].Stack, "TestTrack") {
+		t.Errorf("Expected stack to contain caller Got '%s'", ops[0].Stack)
+	}
+
+	if ops[0].Started.IsZero() {
+		t.Errorf("Expected start time to be recorded")
+	}
+
+	op.Done()
+	m.Done()
+
+	if ops = m.InFlight(); len(ops) != 0 {
+		t.Errorf("Expected no in-flight operations Got '%v'", ops)
+	}
+}
+
+func TestUnbalancedDone(t *testing.T) {
+
+	m := New()
+
+	op := m.Track("twice")
+	op.Done()
+
+	err := catchPanic(op.Done)
+
+	if !errors.Is(err, ErrUnbalancedDone) {
+		t.Fatalf("Expected '%v' Got '%v'", ErrUnbalancedDone, err)
+	}
+
+	if !strings.Contains(err.Error(), `"twice"`) {
+		t.Errorf("Expected error to name the operation Got '%s'", err)
+	}
+
+	if err = catchPanic(m.Done); !errors.Is(err, ErrUnbalancedDone) {
+		t.Errorf("Expected '%v' Got '%v'", ErrUnbalancedDone, err)
+	}
+}
+
+func TestMixedWaitDone(t *testing.T) {
+
+	m := New()
+
+	tok := m.Wait()
+	m.Wait()
+
+	// completes tok's operation, tok then completes the other
+	m.Done()
+
+	if err := catchPanic(tok.Done); err != nil {
+		t.Fatalf("Expected '%v' Got '%v'", nil, err)
+	}
+
+	if n := m.inFlightCount(); n != 0 {
+		t.Errorf("Expected '%d' Got '%d'", 0, n)
+	}
+
+	if err := catchPanic(tok.Done); !errors.Is(err, ErrUnbalancedDone) {
+		t.Errorf("Expected '%v' Got '%v'", ErrUnbalancedDone, err)
+	}
+
+	// a token whose operation was completed in the place of another's completes the next
+	a := m.Wait()
+	b := m.Wait()
+
+	m.Done()
+	a.Done()
+	m.Wait()
+
+	if err := catchPanic(b.Done); err != nil {
+		t.Errorf("Expected '%v' Got '%v'", nil, err)
+	}
+
+	if n := m.inFlightCount(); n != 0 {
+		t.Errorf("Expected '%d' Got '%d'", 0, n)
+	}
+}
+
+func TestWaitDoneTwice(t *testing.T) {
+
+	m := New()
+
+	a := m.Wait()
+	m.Wait()
+
+	a.Done()
+
+	err := catchPanic(a.Done)
+
+	if !errors.Is(err, ErrUnbalancedDone) {
+		t.Fatalf("Expected '%v' Got '%v'", ErrUnbalancedDone, err)
+	}
+
+	if !strings.Contains(err.Error(), `"kms.Wait"`) {
+		t.Errorf("Expected error to name the operation Got '%s'", err)
+	}
+
+	if n := m.inFlightCount(); n != 1 {
+		t.Errorf("Expected '%d' Got '%d'", 1, n)
+	}
+}
+
+func catchPanic(fn func()) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err, _ = r.(error)
+		}
+	}()
+
+	fn()
+
+	return
+}
@@ -0,0 +1,48 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestCheckReadiness(t *testing.T) {
+
+	m := New(WithSignalFn(chanSignalFn(make(chan os.Signal))))
+
+	var calls int
+	errWarming := errors.New("warming")
+
+	m.AddReadinessCheck("cache", func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return errWarming
+		}
+		return nil
+	})
+
+	err := m.CheckReadiness(context.Background())
+
+	var re *ReadinessError
+
+	if !errors.As(err, &re) || re.Name != "cache" || !errors.Is(err, errWarming) {
+		t.Fatalf("Expected '%v' Got '%v'", errWarming, err)
+	}
+
+	if err = m.CheckReadiness(context.Background()); err != nil {
+		t.Fatalf("Expected ready Got '%v'", err)
+	}
+
+	// passed checks are not run again
+	if err = m.CheckReadiness(context.Background()); err != nil || calls != 2 {
+		t.Errorf("Expected '%d' calls Got '%d'", 2, calls)
+	}
+
+	m.Shutdown(nil)
+	m.Listen(true)
+
+	if err = m.CheckReadiness(context.Background()); err != ErrNotReady {
+		t.Errorf("Expected '%v' Got '%v'", ErrNotReady, err)
+	}
+}
@@ -0,0 +1,98 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+// ErrShutdownRequested is the shutdown reason recorded when Shutdown is called with a nil reason.
+var ErrShutdownRequested = errors.New("kms: shutdown requested")
+
+// SignalError is the shutdown reason recorded when a shutdown is initiated by an os.Signal.
+type SignalError struct {
+	Signal os.Signal
+}
+
+// Error returns the signal error's string representation
+func (e *SignalError) Error() string {
+	return "kms: received signal " + signalName(e.Signal)
+}
+
+type reasonKey struct{}
+
+// ReasonFromContext returns the shutdown reason carried by the context, shutdown hooks
+// are passed a context carrying the reason, nil is returned when the context carries no
+// reason.
+func ReasonFromContext(ctx context.Context) error {
+	reason, _ := ctx.Value(reasonKey{}).(error)
+	return reason
+}
+
+// Shutdown initiates the same graceful shutdown as receiving a shutdown signal, recording
+// the reason for the shutdown eg. a fatal error in a component, an admin command or a
+// completed batch job. A nil reason is recorded as ErrShutdownRequested.
+//
+// Only the first reason, whether an os.Signal or an error, is recorded; the shutdown is
+// carried out once Listen or ListenTimeout is running.
+func (m *Manager) Shutdown(reason error) {
+
+	if reason == nil {
+		reason = ErrShutdownRequested
+	}
+
+	m.setReason(reason)
+}
+
+// setReason records the shutdown reason and triggers the shutdown if no
+// reason was previously recorded, returning the recorded reason.
+func (m *Manager) setReason(reason error) error {
+	return m.recordReason(nil, reason)
+}
+
+// recordReason acts as setReason for the lifecycle the trigger belongs to, a nil trigger
+// meaning the current lifecycle; nil is returned if the lifecycle has since been Reset.
+func (m *Manager) recordReason(trigger chan struct{}, reason error) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := m.trigger.Load().(chan struct{})
+
+	if trigger != nil && trigger != current {
+		return nil
+	}
+
+	if m.reason == nil {
+		m.reason = reason
+		close(current)
+	}
+
+	return m.reason
+}
+
+// ShutdownReason returns the reason the shutdown was initiated, nil if a shutdown
+// has not been initiated. When initiated by a signal the reason is a *SignalError.
+func (m *Manager) ShutdownReason() error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.reason
+}
+
+// Shutdown initiates the same graceful shutdown as receiving a shutdown signal, recording
+// the reason for the shutdown eg. a fatal error in a component, an admin command or a
+// completed batch job. A nil reason is recorded as ErrShutdownRequested.
+//
+// Only the first reason, whether an os.Signal or an error, is recorded; the shutdown is
+// carried out once Listen or ListenTimeout is running.
+func Shutdown(reason error) {
+	defaultManager.Shutdown(reason)
+}
+
+// ShutdownReason returns the reason the shutdown was initiated, nil if a shutdown
+// has not been initiated. When initiated by a signal the reason is a *SignalError.
+func ShutdownReason() error {
+	return defaultManager.ShutdownReason()
+}
@@ -0,0 +1,74 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShutdownReason(t *testing.T) {
+
+	m := New(WithSignalFn(chanSignalFn(make(chan os.Signal))))
+
+	errFatal := errors.New("fatal")
+	hookReason := make(chan error, 1)
+
+	m.OnShutdown(PhaseClose, "reason", func(ctx context.Context) error {
+		hookReason <- ReasonFromContext(ctx)
+		return nil
+	})
+
+	if m.ShutdownReason() != nil {
+		t.Errorf("Expected no reason before shutdown")
+	}
+
+	m.Listen(false)
+	m.Shutdown(errFatal)
+	m.Shutdown(errors.New("ignored"))
+
+	select {
+	case <-m.ShutdownComplete():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected shutdown to complete")
+	}
+
+	if err := m.ShutdownReason(); err != errFatal {
+		t.Errorf("Expected '%v' Got '%v'", errFatal, err)
+	}
+
+	if err := context.Cause(m.Context()); err != errFatal {
+		t.Errorf("Expected '%v' Got '%v'", errFatal, err)
+	}
+
+	if err := <-hookReason; err != errFatal {
+		t.Errorf("Expected '%v' Got '%v'", errFatal, err)
+	}
+}
+
+func TestShutdownReasonSignal(t *testing.T) {
+
+	sig := make(chan os.Signal, 1)
+	m := New(WithSignalFn(chanSignalFn(sig)))
+
+	m.Listen(false)
+	sig <- syscall.SIGTERM
+
+	<-m.ShutdownComplete()
+
+	var se *SignalError
+
+	if !errors.As(m.ShutdownReason(), &se) || se.Signal != syscall.SIGTERM {
+		t.Errorf("Expected '%v' Got '%v'", syscall.SIGTERM, m.ShutdownReason())
+	}
+
+	m = New(WithSignalFn(chanSignalFn(sig)))
+	m.Shutdown(nil)
+	m.Listen(true)
+
+	if err := m.ShutdownReason(); err != ErrShutdownRequested {
+		t.Errorf("Expected '%v' Got '%v'", ErrShutdownRequested, err)
+	}
+}
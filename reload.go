@@ -0,0 +1,234 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// ErrShuttingDown is returned when attempting a reload once a shutdown has been initiated.
+var ErrShuttingDown = errors.New("kms: shutting down")
+
+// ReloadError is the error returned when a reload handler fails or exceeds it's timeout.
+type ReloadError struct {
+	Name string
+	Err  error
+}
+
+// Error returns the reload error's string representation
+func (e *ReloadError) Error() string {
+	return fmt.Sprintf("kms: reload handler %q: %s", e.Name, e.Err)
+}
+
+// Unwrap returns the underlying reload error
+func (e *ReloadError) Unwrap() error {
+	return e.Err
+}
+
+// reloadRun is a single run of all reload handlers; Reload callers waiting on
+// the same run share it's result.
+type reloadRun struct {
+	done chan struct{}
+	err  error
+}
+
+// WithReloadSignal sets the signal which triggers a reload, see SetReloadSignal()
+func WithReloadSignal(sig os.Signal) Option {
+	return func(m *Manager) {
+		m.SetReloadSignal(sig)
+	}
+}
+
+// SetReloadSignal sets the signal which triggers a reload once at least one reload handler
+// has been registered; it takes precedence over the signal's mapping within a SignalRouter.
+// Passing nil disables triggering a reload by signal.
+//
+// Default: syscall.SIGHUP
+func (m *Manager) SetReloadSignal(sig os.Signal) {
+	m.mu.Lock()
+	m.reloadSig = sig
+	m.mu.Unlock()
+}
+
+// WithReloadTimeout sets the default timeout of each reload handler, see SetReloadTimeout()
+func WithReloadTimeout(timeout time.Duration) Option {
+	return func(m *Manager) {
+		m.SetReloadTimeout(timeout)
+	}
+}
+
+// SetReloadTimeout sets the default amount of time each reload handler is allowed to run,
+// it can be overridden per handler using HookTimeout().
+//
+// Default: 30 seconds
+func (m *Manager) SetReloadTimeout(timeout time.Duration) {
+	m.mu.Lock()
+	m.reloadTimeout = timeout
+	m.mu.Unlock()
+}
+
+// OnReload registers a handler to be run when a reload is triggered, either by the reload
+// signal or by calling Reload(). Handlers are run serially in the order they were registered.
+//
+// The returned func unregisters the handler.
+func (m *Manager) OnReload(name string, fn HookFunc, opts ...HookOption) (unregister func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.addHook(&m.reloaders, newHook(name, fn, opts))
+}
+
+// Reload runs all registered reload handlers, serially, and blocks until they have completed
+// returning the joined errors, of type *ReloadError, of any failed handlers.
+//
+// A Reload requested while one is already in progress is coalesced into a single follow up
+// reload shared by all callers. ErrShuttingDown is returned once ShutdownInitiated() has closed.
+func (m *Manager) Reload() error {
+
+	if m.shuttingDown() {
+		return ErrShuttingDown
+	}
+
+	m.reloadMu.Lock()
+
+	var run *reloadRun
+
+	switch {
+	case m.reloadCur == nil:
+		run = &reloadRun{done: make(chan struct{})}
+		m.reloadCur = run
+		go m.runReloads(run)
+
+	case m.reloadNext == nil:
+		run = &reloadRun{done: make(chan struct{})}
+		m.reloadNext = run
+
+	default:
+		run = m.reloadNext
+	}
+
+	m.reloadMu.Unlock()
+
+	<-run.done
+
+	return run.err
+}
+
+func (m *Manager) runReloads(run *reloadRun) {
+
+	for run != nil {
+
+		if m.shuttingDown() {
+			run.err = ErrShuttingDown
+		} else {
+			run.err = m.reload()
+		}
+
+		close(run.done)
+
+		m.reloadMu.Lock()
+		m.reloadCur, m.reloadNext = m.reloadNext, nil
+		run = m.reloadCur
+		m.reloadMu.Unlock()
+	}
+}
+
+func (m *Manager) reload() error {
+
+	m.mu.Lock()
+	handlers := append([]hook(nil), m.reloaders...)
+	timeout := m.reloadTimeout
+	m.mu.Unlock()
+
+	start := m.Clock().Now()
+
+	m.emit(Event{Type: EventReloadStarted, Time: start})
+
+	var errs []error
+
+	for _, h := range handlers {
+
+		if h.timeout <= 0 {
+			h.timeout = timeout
+		}
+
+		if err := m.runHook(context.Background(), h, time.Time{}); err != nil {
+			m.Logger().Error("reload handler failed", "handler", h.name, "error", err)
+			errs = append(errs, &ReloadError{Name: h.name, Err: err})
+		}
+	}
+
+	err := errors.Join(errs...)
+
+	m.emit(Event{Type: EventReloadComplete, Duration: m.Clock().Now().Sub(start), Err: err})
+
+	return err
+}
+
+// requestReload is called when a reload is requested by a signal
+func (m *Manager) requestReload(reason error) {
+
+	m.emit(Event{Type: EventReloadRequested, Reason: reason})
+
+	go func() {
+		if err := m.Reload(); errors.Is(err, ErrShuttingDown) {
+			m.Logger().Warn("reload refused", "error", err)
+		}
+	}()
+}
+
+// isReloadSignal returns if the signal is the configured reload signal and at least one
+// reload handler is registered.
+func (m *Manager) isReloadSignal(sig os.Signal) bool {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.reloadSig != nil && sig == m.reloadSig && len(m.reloaders) > 0
+}
+
+func (m *Manager) reloadSignal() os.Signal {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.reloadSig
+}
+
+func (m *Manager) shuttingDown() bool {
+	select {
+	case <-m.ShutdownInitiated():
+		return true
+	default:
+		return false
+	}
+}
+
+func defaultReloadSignal() os.Signal {
+	return syscall.SIGHUP
+}
+
+// SetReloadSignal sets the signal which triggers a reload once at least one reload handler
+// has been registered, passing nil disables triggering a reload by signal.
+//
+// Default: syscall.SIGHUP
+func SetReloadSignal(sig os.Signal) {
+	defaultManager.SetReloadSignal(sig)
+}
+
+// OnReload registers a handler to be run when a reload is triggered, either by the reload
+// signal or by calling Reload(). Handlers are run serially in the order they were registered.
+func OnReload(name string, fn HookFunc, opts ...HookOption) (unregister func()) {
+	return defaultManager.OnReload(name, fn, opts...)
+}
+
+// Reload runs all registered reload handlers, serially, and blocks until they have completed
+// returning the joined errors, of type *ReloadError, of any failed handlers.
+//
+// A Reload requested while one is already in progress is coalesced into a single follow up
+// reload shared by all callers. ErrShuttingDown is returned once ShutdownInitiated() has closed.
+func Reload() error {
+	return defaultManager.Reload()
+}
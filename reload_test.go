@@ -0,0 +1,152 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+
+	m := New()
+
+	var order []string
+	errFailed := errors.New("failed")
+
+	m.OnReload("first", func(ctx context.Context) error {
+		order = append(order, "first")
+		return nil
+	})
+
+	m.OnReload("second", func(ctx context.Context) error {
+		order = append(order, "second")
+		return errFailed
+	})
+
+	m.OnReload("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, HookTimeout(time.Millisecond*10))
+
+	err := m.Reload()
+
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Errorf("Expected handlers to run serially in order Got '%v'", order)
+	}
+
+	var re *ReloadError
+
+	if !errors.As(err, &re) || re.Name != "second" || !errors.Is(err, errFailed) {
+		t.Errorf("Expected '%v' Got '%v'", errFailed, err)
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected '%v' Got '%v'", context.DeadlineExceeded, err)
+	}
+
+	m.OnReload("removed", func(ctx context.Context) error {
+		t.Errorf("Expected unregistered handler not to run")
+		return nil
+	})()
+
+	order = nil
+	m.Reload()
+
+	if len(order) != 2 {
+		t.Errorf("Expected '%d' handlers to run Got '%v'", 2, order)
+	}
+}
+
+func TestReloadCoalesced(t *testing.T) {
+
+	m := New()
+
+	var runs int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	m.OnReload("blocking", func(ctx context.Context) error {
+		if atomic.AddInt32(&runs, 1) == 1 {
+			close(started)
+			<-release
+		}
+		return nil
+	})
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.Reload()
+	}()
+
+	<-started
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Reload()
+		}()
+	}
+
+	// allow the coalesced reloads to queue up
+	time.Sleep(time.Millisecond * 50)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&runs); n != 2 {
+		t.Errorf("Expected '%d' Got '%d'", 2, n)
+	}
+}
+
+func TestReloadRefusedWhileShuttingDown(t *testing.T) {
+
+	m := New(WithSignalFn(chanSignalFn(make(chan os.Signal))))
+
+	m.OnReload("noop", func(ctx context.Context) error { return nil })
+
+	m.Shutdown(nil)
+	m.Listen(true)
+
+	if err := m.Reload(); err != ErrShuttingDown {
+		t.Errorf("Expected '%v' Got '%v'", ErrShuttingDown, err)
+	}
+}
+
+func TestReloadSignal(t *testing.T) {
+
+	m := New(WithReloadSignal(syscall.SIGUSR2))
+
+	reloaded := make(chan struct{}, 1)
+
+	m.OnReload("signal", func(ctx context.Context) error {
+		reloaded <- struct{}{}
+		return nil
+	})
+
+	m.Listen(false)
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected reload signal to trigger reload")
+	}
+
+	select {
+	case <-m.ShutdownInitiated():
+		t.Fatalf("Expected reload signal not to initiate shutdown")
+	default:
+	}
+
+	m.Shutdown(nil)
+	<-m.ShutdownComplete()
+}
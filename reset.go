@@ -0,0 +1,63 @@
+package kms
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrShutdownInProgress is returned by Reset once a shutdown has been initiated and until it
+// has completed.
+var ErrShutdownInProgress = errors.New("kms: shutdown in progress")
+
+// Reset re-arms the Manager so that it's lifecycle can be run again; the notification channels,
+// contexts, shutdown reason, hook and goroutine errors and state are recreated, and a Listen or
+// ListenTimeout still waiting for a signal is stopped, so Listen must be called again. A blocking
+// Listen or ListenTimeout returns, without exiting, rather than waiting on the old lifecycle.
+//
+// Registered hooks, readiness checks, gauges, subscriptions and options are kept.
+//
+// ErrShutdownInProgress is returned once a shutdown has been initiated, including a Shutdown
+// requested before Listen was called, until it has completed.
+//
+// useful for tests and in-process supervisors that run the full lifecycle many times.
+func (m *Manager) Reset() error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.reason != nil && m.State() != StateStopped {
+		return ErrShutdownInProgress
+	}
+
+	close(m.stop.Load().(chan struct{}))
+
+	m.reason = nil
+	m.hookErrs = nil
+	m.goErrs = nil
+	m.arm()
+
+	return nil
+}
+
+// arm stores the per lifecycle values, must be called with mu held or before the Manager
+// is shared.
+func (m *Manager) arm() {
+	m.notify.Store(make(chan struct{}))
+	m.trigger.Store(make(chan struct{}))
+	m.done.Store(make(chan struct{}))
+	m.drain.Store(make(chan struct{}))
+	m.stop.Store(make(chan struct{}))
+	m.ctx.Store(newShutdownContext())
+	m.hardCtx.Store(newShutdownContext())
+	m.shutdownAt.Store(time.Time{})
+	m.drainAt.Store(time.Time{})
+	m.drainDur.Store(time.Duration(0))
+	m.state.Store(StateRunning)
+}
+
+// Reset re-arms the package so that it's lifecycle can be run again, see Manager.Reset()
+//
+// ErrShutdownInProgress is returned once a shutdown has been initiated and until it has completed.
+func Reset() error {
+	return defaultManager.Reset()
+}
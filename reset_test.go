@@ -0,0 +1,115 @@
+package kms
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReset(t *testing.T) {
+
+	sig := make(chan os.Signal, 1)
+	m := New(WithSignalFn(chanSignalFn(sig)))
+
+	for i := 0; i < 3; i++ {
+
+		op := m.Track("op")
+
+		m.Listen(false)
+		sig <- syscall.SIGTERM
+		<-m.DrainStarted()
+
+		if err := m.Reset(); err != ErrShutdownInProgress {
+			t.Fatalf("Expected '%v' Got '%v'", ErrShutdownInProgress, err)
+		}
+
+		op.Done()
+		<-m.ShutdownComplete()
+
+		if err := m.Reset(); err != nil {
+			t.Fatalf("Expected reset Got '%v'", err)
+		}
+
+		if m.State() != StateRunning || m.ShutdownReason() != nil || m.Context().Err() != nil {
+			t.Fatalf("Expected a fresh lifecycle Got '%s' '%v'", m.State(), m.ShutdownReason())
+		}
+
+		if _, ok := m.ShutdownStarted(); ok {
+			t.Fatalf("Expected shutdown start to be reset")
+		}
+	}
+}
+
+func TestResetStopsListen(t *testing.T) {
+
+	sig := make(chan os.Signal, 2)
+	m := New(
+		WithSignalFn(chanSignalFn(sig)),
+		WithHardShutdown(false),
+	)
+
+	m.Listen(false)
+
+	if err := m.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the previous Listen no longer acts on signals
+	sig <- syscall.SIGTERM
+
+	select {
+	case <-m.ShutdownInitiated():
+		t.Fatalf("Expected signal to be ignored until Listen is called again")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	// the previous Listen may have consumed, and discarded, the first signal
+	m.Listen(false)
+	sig <- syscall.SIGTERM
+
+	select {
+	case <-m.ShutdownComplete():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected shutdown to complete")
+	}
+}
+
+func TestResetStopsBlockingListen(t *testing.T) {
+
+	var exited bool
+
+	p := DefaultExitPolicy()
+	p.ExitOnComplete = true
+
+	m := New(
+		WithSignalFn(chanSignalFn(make(chan os.Signal))),
+		WithExitPolicy(p),
+		WithExitFunc(func(int) { exited = true }),
+	)
+
+	returned := make(chan struct{})
+
+	go func() {
+		m.Listen(true)
+		close(returned)
+	}()
+
+	// Reset may run before the Listen does, in which case it blocks on the new lifecycle.
+	for {
+		if err := m.Reset(); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case <-returned:
+		case <-time.After(time.Millisecond * 10):
+			continue
+		}
+		break
+	}
+
+	if exited {
+		t.Errorf("Expected a blocking Listen stopped by Reset not to exit")
+	}
+}
@@ -0,0 +1,191 @@
+package kms
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// SignalAction is the behaviour a SignalRouter applies when a signal is received.
+type SignalAction uint8
+
+// Signal actions
+const (
+	// SignalShutdown initiates a graceful shutdown, a second shutdown signal causes
+	// a hard shutdown when allowed, see AllowSignalHardShutdown().
+	SignalShutdown SignalAction = iota
+
+	// SignalImmediate terminates the process immediately without draining.
+	SignalImmediate
+
+	// SignalReload requests a reload of the application, see OnReload().
+	SignalReload
+
+	// SignalIgnore ignores the signal.
+	SignalIgnore
+)
+
+// String returns the name of the SignalAction
+func (a SignalAction) String() string {
+	switch a {
+	case SignalShutdown:
+		return "shutdown"
+	case SignalImmediate:
+		return "immediate"
+	case SignalReload:
+		return "reload"
+	case SignalIgnore:
+		return "ignore"
+	default:
+		return fmt.Sprintf("SignalAction(%d)", uint8(a))
+	}
+}
+
+type route struct {
+	action SignalAction
+	fn     func(os.Signal)
+}
+
+// SignalRouter maps each os.Signal to a SignalAction or custom callback, it is used
+// by registering it's SignalFn() with SetSignalFn().
+//
+// eg. SIGHUP to reload and SIGUSR1 to dump state
+//
+//	r := kms.NewSignalRouter(kms.Default()).
+//		Handle(kms.SignalReload, syscall.SIGHUP).
+//		HandleFunc(dumpState, syscall.SIGUSR1)
+//
+//	kms.SetSignalFn(r.SignalFn())
+type SignalRouter struct {
+	m      *Manager
+	mu     sync.Mutex
+	routes map[os.Signal]route
+}
+
+// NewSignalRouter returns a new SignalRouter for the provided Manager, pre-configured
+// with the default behaviour of routing syscall.SIGINT, syscall.SIGTERM and syscall.SIGHUP
+// to SignalShutdown.
+func NewSignalRouter(m *Manager) *SignalRouter {
+
+	r := &SignalRouter{
+		m:      m,
+		routes: make(map[os.Signal]route),
+	}
+
+	return r.Handle(SignalShutdown, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+}
+
+// Handle maps the provided signals to the SignalAction, replacing any existing mapping.
+func (r *SignalRouter) Handle(action SignalAction, sigs ...os.Signal) *SignalRouter {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, sig := range sigs {
+		r.routes[sig] = route{action: action}
+	}
+
+	return r
+}
+
+// HandleFunc maps the provided signals to a custom callback, replacing any existing mapping.
+// Each invocation of the callback is run within it's own goroutine.
+func (r *SignalRouter) HandleFunc(fn func(os.Signal), sigs ...os.Signal) *SignalRouter {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, sig := range sigs {
+		r.routes[sig] = route{fn: fn}
+	}
+
+	return r
+}
+
+// SignalFn returns the SignalFn to register using SetSignalFn(); only signals routed to
+// SignalShutdown are delivered to the returned channel.
+func (r *SignalRouter) SignalFn() SignalFn {
+	return func() <-chan os.Signal {
+
+		r.mu.Lock()
+
+		sigs := make([]os.Signal, 0, len(r.routes))
+		for sig := range r.routes {
+			sigs = append(sigs, sig)
+		}
+
+		r.mu.Unlock()
+
+		if sig := r.m.reloadSignal(); sig != nil {
+			sigs = append(sigs, sig)
+		}
+
+		raw := make(chan os.Signal, 1)
+		signal.Notify(raw, sigs...)
+
+		// buffered for the shutdown and hard shutdown signals
+		s := make(chan os.Signal, 2)
+		complete := r.m.ShutdownComplete()
+		stop := r.m.stop.Load().(chan struct{})
+
+		go func() {
+
+			defer close(s)
+			defer signal.Stop(raw)
+
+			for {
+				select {
+				case sig := <-raw:
+					r.dispatch(sig, s)
+				case <-complete:
+					return
+				case <-stop:
+					return
+				}
+			}
+		}()
+
+		return s
+	}
+}
+
+func (r *SignalRouter) dispatch(sig os.Signal, s chan<- os.Signal) {
+
+	if r.m.isReloadSignal(sig) {
+		r.m.requestReload(&SignalError{Signal: sig})
+		return
+	}
+
+	r.mu.Lock()
+	rt, ok := r.routes[sig]
+	r.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if rt.fn != nil {
+		go rt.fn(sig)
+		return
+	}
+
+	switch rt.action {
+	case SignalShutdown:
+		// never block signal delivery, same as signal.Notify
+		select {
+		case s <- sig:
+		default:
+		}
+
+	case SignalImmediate:
+		r.m.emit(Event{Type: EventHardShutdownRequested, Signal: sig})
+		reason := &SignalError{Signal: sig}
+		r.m.setReason(reason)
+		r.m.forceExit(OutcomeForced, reason)
+
+	case SignalReload:
+		r.m.requestReload(&SignalError{Signal: sig})
+	}
+}
@@ -0,0 +1,94 @@
+package kms
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSignalRouter(t *testing.T) {
+
+	m := New()
+
+	custom := make(chan os.Signal, 1)
+
+	r := NewSignalRouter(m).
+		HandleFunc(func(sig os.Signal) { custom <- sig }, syscall.SIGUSR1).
+		Handle(SignalShutdown, syscall.SIGUSR2)
+
+	m.SetSignalFn(r.SignalFn())
+	m.Listen(false)
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+
+	select {
+	case sig := <-custom:
+		if sig != syscall.SIGUSR1 {
+			t.Errorf("Expected '%v' Got '%v'", syscall.SIGUSR1, sig)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected custom callback to be called")
+	}
+
+	select {
+	case <-m.ShutdownInitiated():
+		t.Fatalf("Expected custom signal not to initiate shutdown")
+	default:
+	}
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+
+	select {
+	case <-m.ShutdownComplete():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected shutdown to complete")
+	}
+}
+
+func TestSignalRouterImmediate(t *testing.T) {
+
+	exited := make(chan int, 1)
+
+	m := New(WithExitFunc(func(code int) { exited <- code }))
+	r := NewSignalRouter(m).Handle(SignalImmediate, syscall.SIGQUIT)
+
+	m.Wait()
+	r.dispatch(syscall.SIGQUIT, make(chan os.Signal, 1))
+
+	select {
+	case code := <-exited:
+		if code != 1 {
+			t.Errorf("Expected '%d' Got '%d'", 1, code)
+		}
+	default:
+		t.Fatalf("Expected immediate exit")
+	}
+
+	if m.HardStopContext().Err() == nil {
+		t.Errorf("Expected hard stop context to be cancelled")
+	}
+}
+
+func TestSignalRouterIgnore(t *testing.T) {
+
+	m := New()
+	r := NewSignalRouter(m).Handle(SignalIgnore, syscall.SIGHUP)
+
+	s := make(chan os.Signal, 1)
+	r.dispatch(syscall.SIGHUP, s)
+	r.dispatch(syscall.SIGTERM, s)
+
+	select {
+	case sig := <-s:
+		if sig != syscall.SIGTERM {
+			t.Errorf("Expected '%v' Got '%v'", syscall.SIGTERM, sig)
+		}
+	default:
+		t.Fatalf("Expected shutdown signal to be delivered")
+	}
+
+	if len(s) != 0 {
+		t.Errorf("Expected ignored signal not to be delivered")
+	}
+}
@@ -0,0 +1,97 @@
+package kms
+
+import (
+	"fmt"
+	"time"
+)
+
+// State is the lifecycle state of a Manager
+type State uint8
+
+// Lifecycle states, in the order they are transitioned through
+const (
+	// StateRunning means a shutdown has not been initiated.
+	StateRunning State = iota
+
+	// StateLameDuck means a shutdown has been initiated but listeners continue to accept
+	// connections for the lame-duck period, see SetLameDuck().
+	StateLameDuck
+
+	// StateDraining means listeners have been closed and in-flight operations are being waited on.
+	StateDraining
+
+	// StateStopped means the shutdown has completed.
+	StateStopped
+)
+
+// String returns the name of the State
+func (s State) String() string {
+	switch s {
+	case StateRunning:
+		return "running"
+	case StateLameDuck:
+		return "lame-duck"
+	case StateDraining:
+		return "draining"
+	case StateStopped:
+		return "stopped"
+	default:
+		return fmt.Sprintf("State(%d)", uint8(s))
+	}
+}
+
+// MarshalText returns the name of the State, see String()
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// State returns the current lifecycle state of the Manager.
+func (m *Manager) State() State {
+	return m.state.Load().(State)
+}
+
+// WithLameDuck sets the lame-duck period, see SetLameDuck()
+func WithLameDuck(d time.Duration) Option {
+	return func(m *Manager) {
+		m.SetLameDuck(d)
+	}
+}
+
+// SetLameDuck sets the duration of the lame-duck period between a shutdown being initiated and
+// draining starting. During the period ShutdownInitiated() is closed, so readiness reports not-ready,
+// but kmsnet listeners continue to accept connections, giving load balancers such as Kubernetes
+// endpoints time to stop routing new connections to the process.
+//
+// The ListenTimeout wait duration starts once the lame-duck period has ended.
+//
+// Default: 0, draining starts immediately
+func (m *Manager) SetLameDuck(d time.Duration) {
+	m.lameDuck.Store(d)
+}
+
+// DrainStarted returns a notification channel for the Manager which will be closed/notified
+// once the lame-duck period has ended and in-flight operations are being drained; it is when
+// listeners should stop accepting new connections.
+func (m *Manager) DrainStarted() <-chan struct{} {
+	return m.drain.Load().(chan struct{})
+}
+
+// CurrentState returns the current lifecycle state of the package.
+func CurrentState() State {
+	return defaultManager.State()
+}
+
+// SetLameDuck sets the duration of the lame-duck period between a shutdown being initiated and
+// draining starting, see Manager.SetLameDuck()
+//
+// Default: 0, draining starts immediately
+func SetLameDuck(d time.Duration) {
+	defaultManager.SetLameDuck(d)
+}
+
+// DrainStarted returns a notification channel for the package which will be closed/notified
+// once the lame-duck period has ended and in-flight operations are being drained; it is when
+// listeners should stop accepting new connections.
+func DrainStarted() <-chan struct{} {
+	return defaultManager.DrainStarted()
+}
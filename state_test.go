@@ -0,0 +1,52 @@
+package kms
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLameDuck(t *testing.T) {
+
+	sig := make(chan os.Signal, 1)
+	m := New(
+		WithSignalFn(chanSignalFn(sig)),
+		WithLameDuck(time.Millisecond*100),
+	)
+
+	if m.State() != StateRunning {
+		t.Errorf("Expected '%s' Got '%s'", StateRunning, m.State())
+	}
+
+	m.ListenTimeout(false, time.Minute)
+	sig <- syscall.SIGTERM
+
+	<-m.ShutdownInitiated()
+
+	if m.State() != StateLameDuck {
+		t.Errorf("Expected '%s' Got '%s'", StateLameDuck, m.State())
+	}
+
+	if deadline, ok := m.Context().Deadline(); !ok || time.Until(deadline) <= time.Minute {
+		t.Errorf("Expected deadline to include the lame-duck period")
+	}
+
+	select {
+	case <-m.DrainStarted():
+		t.Fatalf("Expected draining to wait for the lame-duck period")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	select {
+	case <-m.DrainStarted():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected draining to start after the lame-duck period")
+	}
+
+	<-m.ShutdownComplete()
+
+	if m.State() != StateStopped {
+		t.Errorf("Expected '%s' Got '%s'", StateStopped, m.State())
+	}
+}
@@ -0,0 +1,129 @@
+package kms
+
+import (
+	"expvar"
+	"time"
+)
+
+// RuntimeStats is a point in time snapshot of a Managers runtime statistics, see Stats()
+type RuntimeStats struct {
+	State State `json:"state"`
+
+	// InFlight is the number of in-flight operations.
+	InFlight int `json:"in_flight"`
+
+	// Waits and Dones are the total number of operations started, via Wait() or Track(),
+	// and completed.
+	Waits uint64 `json:"waits"`
+	Dones uint64 `json:"dones"`
+
+	// ShutdownStarted is the time the shutdown was initiated, zero if it has not been.
+	ShutdownStarted time.Time `json:"shutdown_started,omitzero"`
+
+	// DrainDuration is how long in-flight operations took to drain, or have been draining
+	// for when the drain is still in progress; zero if draining has not started.
+	DrainDuration time.Duration `json:"drain_duration,omitzero"`
+
+	// Gauges are the current values of all registered gauges eg. the active connections of
+	// each kmsnet listener, see RegisterGauge().
+	Gauges map[string]int64 `json:"gauges,omitempty"`
+}
+
+type gauge struct {
+	fn func() int64
+}
+
+// RegisterGauge registers a named value to be reported by Stats(), registering a gauge
+// using an existing name replaces it. The returned function unregisters the gauge.
+//
+// eg. kmsnet registers the number of active connections of each listener.
+func (m *Manager) RegisterGauge(name string, fn func() int64) (unregister func()) {
+
+	g := &gauge{fn: fn}
+
+	m.mu.Lock()
+
+	if m.gauges == nil {
+		m.gauges = make(map[string]*gauge)
+	}
+
+	m.gauges[name] = g
+	m.mu.Unlock()
+
+	return func() {
+		m.mu.Lock()
+		if m.gauges[name] == g {
+			delete(m.gauges, name)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// Stats returns a snapshot of the Managers runtime statistics.
+func (m *Manager) Stats() RuntimeStats {
+
+	// load dones first so in-flight can never be negative
+	dones := m.dones.Load()
+	waits := m.waits.Load()
+
+	s := RuntimeStats{
+		State:    m.State(),
+		InFlight: int(waits - dones),
+		Waits:    waits,
+		Dones:    dones,
+	}
+
+	s.ShutdownStarted, _ = m.ShutdownStarted()
+
+	if d := m.drainDur.Load().(time.Duration); d > 0 {
+		s.DrainDuration = d
+	} else if at := m.drainAt.Load().(time.Time); !at.IsZero() {
+		s.DrainDuration = m.Clock().Now().Sub(at)
+	}
+
+	m.mu.Lock()
+
+	gauges := make(map[string]*gauge, len(m.gauges))
+	for name, g := range m.gauges {
+		gauges[name] = g
+	}
+
+	m.mu.Unlock()
+
+	if len(gauges) > 0 {
+
+		s.Gauges = make(map[string]int64, len(gauges))
+
+		// gauges are called without holding the lock as they may call back into the Manager
+		for name, g := range gauges {
+			s.Gauges[name] = g.fn()
+		}
+	}
+
+	return s
+}
+
+// PublishExpvar publishes the Managers runtime statistics under the provided expvar name,
+// see Stats(). Like expvar.Publish it panics if the name is already in use.
+func (m *Manager) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return m.Stats()
+	}))
+}
+
+// RegisterGauge registers a named value to be reported by Stats(), registering a gauge
+// using an existing name replaces it. The returned function unregisters the gauge.
+func RegisterGauge(name string, fn func() int64) (unregister func()) {
+	return defaultManager.RegisterGauge(name, fn)
+}
+
+// Stats returns a snapshot of the package's runtime statistics.
+func Stats() RuntimeStats {
+	return defaultManager.Stats()
+}
+
+// PublishExpvar publishes the package's runtime statistics under the provided expvar name,
+// see Stats(). Like expvar.Publish it panics if the name is already in use.
+func PublishExpvar(name string) {
+	defaultManager.PublishExpvar(name)
+}
@@ -0,0 +1,71 @@
+package kms
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+)
+
+var expvarRuns atomic.Int32
+
+func TestStats(t *testing.T) {
+
+	sig := make(chan os.Signal, 1)
+	m := New(WithSignalFn(chanSignalFn(sig)))
+
+	unregister := m.RegisterGauge("queue", func() int64 { return 7 })
+
+	m.Wait()
+	op := m.Track("op")
+	m.Done()
+
+	s := m.Stats()
+
+	if s.State != StateRunning || s.InFlight != 1 || s.Waits != 2 || s.Dones != 1 {
+		t.Errorf("Expected running with '%d' in-flight Got '%+v'", 1, s)
+	}
+
+	if !s.ShutdownStarted.IsZero() || s.DrainDuration != 0 {
+		t.Errorf("Expected no shutdown Got '%+v'", s)
+	}
+
+	if s.Gauges["queue"] != 7 {
+		t.Errorf("Expected '%d' Got '%d'", 7, s.Gauges["queue"])
+	}
+
+	unregister()
+
+	m.Listen(false)
+	sig <- syscall.SIGTERM
+	<-m.DrainStarted()
+
+	if s = m.Stats(); s.ShutdownStarted.IsZero() || s.Gauges != nil {
+		t.Errorf("Expected shutdown started and no gauges Got '%+v'", s)
+	}
+
+	op.Done()
+	<-m.ShutdownComplete()
+
+	if s = m.Stats(); s.State != StateStopped || s.InFlight != 0 || s.Dones != 2 || s.DrainDuration <= 0 {
+		t.Errorf("Expected stopped and drained Got '%+v'", s)
+	}
+
+	// expvar names can only be published once per process, eg. when run with -count
+	name := fmt.Sprintf("kms_test_%d", expvarRuns.Add(1))
+
+	m.PublishExpvar(name)
+
+	var published map[string]any
+
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &published); err != nil {
+		t.Fatal(err)
+	}
+
+	if published["state"] != "stopped" || published["waits"] != float64(2) {
+		t.Errorf("Expected published stats Got '%v'", published)
+	}
+}
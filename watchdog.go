@@ -0,0 +1,128 @@
+package kms
+
+import (
+	"runtime"
+	"time"
+)
+
+// WithStuckOperationAge sets the age after which in-flight operations are reported as stuck,
+// see SetStuckOperationAge()
+func WithStuckOperationAge(age time.Duration) Option {
+	return func(m *Manager) {
+		m.SetStuckOperationAge(age)
+	}
+}
+
+// SetStuckOperationAge enables a watchdog, started by Listen and ListenTimeout, which reports
+// in-flight operations older than age while the process is running normally; each operation is
+// reported once, with the stack captured when it was started, as an EventOperationStuck event.
+//
+// useful for finding a forgotten Done() before it hangs a shutdown.
+//
+// Default: 0, disabled
+func (m *Manager) SetStuckOperationAge(age time.Duration) {
+	m.stuckAge.Store(age)
+}
+
+// WithLeakDetection enables reporting operations which are garbage collected before Done
+// was called, see SetLeakDetection()
+func WithLeakDetection(enable bool) Option {
+	return func(m *Manager) {
+		m.SetLeakDetection(enable)
+	}
+}
+
+// SetLeakDetection sets whether a finalizer is attached to each Operation returned by Track() and
+// Wait() which reports, as an EventOperationLeaked event, when the token is garbage collected before
+// Done was called. It adds overhead to every Track() and Wait() and is intended for debugging.
+//
+// Once Done() has been called without a token operations started via Wait() are no longer
+// reported, as any of them may be completed that way; see SetStuckOperationAge() to find those.
+// Until then a Wait() token dropped in favour of a later Done() is reported.
+//
+// Default: false
+func (m *Manager) SetLeakDetection(enable bool) {
+	m.leakCheck.Store(enable)
+}
+
+// watchdog reports stuck operations until a shutdown is initiated or the Manager is Reset.
+func (m *Manager) watchdog(age time.Duration, notify, stop <-chan struct{}) {
+
+	t := m.Clock().NewTimer(age / 2)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C():
+			m.reportStuck(age)
+			t.Reset(age / 2)
+		case <-notify:
+			return
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (m *Manager) reportStuck(age time.Duration) {
+
+	now := m.Clock().Now()
+	cutoff := now.Add(-age)
+
+	var stuck []*operation
+
+	m.opsMu.Lock()
+
+	for _, op := range m.ops {
+		if !op.stuck && op.started.Before(cutoff) {
+			op.stuck = true
+			stuck = append(stuck, op)
+		}
+	}
+
+	m.opsMu.Unlock()
+
+	for _, op := range stuck {
+		m.emit(Event{Type: EventOperationStuck, Operation: op.info(now)})
+	}
+}
+
+// watchLeak attaches a finalizer to the token reporting if it is collected while it's
+// operation is still in-flight.
+func (m *Manager) watchLeak(o *Operation) {
+
+	if !m.leakCheck.Load().(bool) {
+		return
+	}
+
+	runtime.SetFinalizer(o, func(o *Operation) {
+
+		m.opsMu.Lock()
+		_, leaked := m.ops[o.op.id]
+		m.opsMu.Unlock()
+
+		if o.op.anon != nil && m.anonDone.Load() {
+			return
+		}
+
+		if leaked {
+			m.emit(Event{Type: EventOperationLeaked, Operation: o.op.info(m.Clock().Now())})
+		}
+	})
+}
+
+// SetStuckOperationAge enables a watchdog, started by Listen and ListenTimeout, which reports
+// in-flight operations older than age while the process is running normally.
+//
+// Default: 0, disabled
+func SetStuckOperationAge(age time.Duration) {
+	defaultManager.SetStuckOperationAge(age)
+}
+
+// SetLeakDetection sets whether operations returned by Track() and Wait() are reported when
+// garbage collected before Done was called.
+//
+// Default: false
+func SetLeakDetection(enable bool) {
+	defaultManager.SetLeakDetection(enable)
+}
@@ -0,0 +1,99 @@
+package kms
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStuckOperations(t *testing.T) {
+
+	m := New(
+		WithSignalFn(chanSignalFn(make(chan os.Signal))),
+		WithStuckOperationAge(time.Millisecond*50),
+	)
+
+	sub := m.Subscribe()
+	defer sub.Close()
+
+	m.Wait()
+	m.Listen(false)
+
+	select {
+	case e := <-sub.Events():
+		if e.Type != EventOperationStuck || e.Operation.Name != "kms.Wait" || !strings.Contains(e.Operation.Stack, "TestStuckOperations") {
+			t.Errorf("Expected stuck operation started by this test Got '%s' '%+v'", e.Type, e.Operation)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected stuck operation to be reported")
+	}
+
+	// each operation is only reported once
+	select {
+	case e := <-sub.Events():
+		t.Errorf("Expected no further events Got '%s'", e.Type)
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	m.Done()
+}
+
+func TestLeakedOperations(t *testing.T) {
+
+	m := New(
+		WithSignalFn(chanSignalFn(make(chan os.Signal))),
+		WithLeakDetection(true),
+	)
+
+	sub := m.Subscribe()
+	defer sub.Close()
+
+	m.Track("forgotten")
+	m.Track("completed").Done()
+
+	expectLeaked(t, sub, "forgotten")
+
+	m.Wait()
+	m.Wait().Done()
+
+	expectLeaked(t, sub, "kms.Wait")
+
+	// once Done is used without a token Wait() tokens are interchangeable
+	m.Wait()
+	m.Done()
+	m.Wait()
+
+	for i := 0; i < 5; i++ {
+		runtime.GC()
+
+		select {
+		case e := <-sub.Events():
+			t.Fatalf("Expected no leak to be reported Got '%s' '%s'", e.Type, e.Operation.Name)
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+}
+
+func expectLeaked(t *testing.T, sub *Subscription, name string) {
+
+	t.Helper()
+
+	deadline := time.After(time.Second * 5)
+
+	for {
+		runtime.GC()
+
+		select {
+		case e := <-sub.Events():
+			if e.Type != EventOperationLeaked || e.Operation.Name != name {
+				t.Fatalf("Expected leaked operation '%s' Got '%s' '%s'", name, e.Type, e.Operation.Name)
+			}
+			return
+		case <-deadline:
+			t.Fatalf("Expected leaked operation '%s' to be reported", name)
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+}